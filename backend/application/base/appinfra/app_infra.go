@@ -36,7 +36,8 @@ import (
 	"github.com/coze-dev/coze-studio/backend/infra/contract/document/parser"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/imagex"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/modelmgr"
-	oceanbase "github.com/coze-dev/coze-studio/backend/infra/impl/cache/oceanbase"
+	"github.com/coze-dev/coze-studio/backend/infra/impl/cache/lru"
+	_ "github.com/coze-dev/coze-studio/backend/infra/impl/cache/oceanbase"
 	"github.com/coze-dev/coze-studio/backend/infra/impl/cache/redis"
 	"github.com/coze-dev/coze-studio/backend/infra/impl/coderunner/direct"
 	"github.com/coze-dev/coze-studio/backend/infra/impl/coderunner/sandbox"
@@ -69,6 +70,16 @@ type AppDependencies struct {
 	ParserManager         parser.Manager
 }
 
+// init registers the Redis cache backend under the name "redis". Redis has
+// no local package of its own to register from (it wraps the external
+// go-redis client directly), so this is its one registration point rather
+// than appinfra hard-coding it as the non-oceanbase case.
+func init() {
+	cache.RegisterFactory("redis", func(db *gorm.DB) (cache.Cmdable, error) {
+		return redis.New(), nil
+	})
+}
+
 func Init(ctx context.Context) (*AppDependencies, error) {
 	deps := &AppDependencies{}
 	var err error
@@ -78,23 +89,26 @@ func Init(ctx context.Context) (*AppDependencies, error) {
 		return nil, err
 	}
 
-	// 检查是否使用OceanBase作为缓存后端，默认为oceanbase
+	// 缓存后端通过 cache.Factory 注册表解析：CACHE_BACKEND 选择主后端（默认
+	// oceanbase），新增后端只需在自己包的init()里注册，不用改这里。再用
+	// cache.WithHealthCheck包一层，主后端持续探测失败时自动降级到进程内LRU
+	// （可读写、数据不是主后端的镜像，纯粹保命），探测恢复后自动切回，取代了
+	// 之前"启动时选一次、挂了就全程不可用"的做法。CACHE_HEALTHCHECK=off可以
+	// 关掉这层自动降级，适合不想被静默切后端的部署。
 	cacheBackend := os.Getenv("CACHE_BACKEND")
 	if cacheBackend == "" {
-		cacheBackend = "oceanbase" // 默认使用OceanBase
+		cacheBackend = "oceanbase"
 	}
 
-	if cacheBackend == "oceanbase" {
-		// 使用OceanBase作为缓存后端
-		logs.Infof("Using OceanBase as cache backend")
-		if err := oceanbase.InitOceanBaseCache(deps.DB); err != nil {
-			return nil, fmt.Errorf("init OceanBase cache failed, err=%w", err)
-		}
-		deps.CacheCli = oceanbase.GetCacheCmdable()
-	} else {
-		// 使用Redis作为缓存后端
-		logs.Infof("Using Redis as cache backend")
-		deps.CacheCli = redis.New()
+	primaryCache, err := cache.NewCmdable(cacheBackend, deps.DB)
+	if err != nil {
+		return nil, fmt.Errorf("init cache backend %q failed, err=%w", cacheBackend, err)
+	}
+	logs.Infof("Using %s as cache backend", cacheBackend)
+
+	deps.CacheCli = primaryCache
+	if os.Getenv("CACHE_HEALTHCHECK") != "off" {
+		deps.CacheCli = cache.WithHealthCheck(primaryCache, lru.New(0), cache.HealthCheckConfig{})
 	}
 
 	deps.IDGenSVC, err = idgen.New(deps.CacheCli)
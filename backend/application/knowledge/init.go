@@ -18,7 +18,6 @@ package knowledge
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -28,11 +27,11 @@ import (
 	"github.com/cloudwego/eino-ext/components/embedding/ark"
 	ollamaEmb "github.com/cloudwego/eino-ext/components/embedding/ollama"
 	"github.com/cloudwego/eino-ext/components/embedding/openai"
-	"github.com/cloudwego/eino/components/prompt"
-	"github.com/cloudwego/eino/schema"
 	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/qdrant/go-client/qdrant"
 	"github.com/volcengine/volc-sdk-golang/service/vikingdb"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"github.com/coze-dev/coze-studio/backend/application/internal"
@@ -42,26 +41,37 @@ import (
 	"github.com/coze-dev/coze-studio/backend/infra/contract/document/nl2sql"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/document/ocr"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/document/parser"
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/rerank"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/embedding"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/es"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/idgen"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/imagex"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/messages2query"
+	"github.com/coze-dev/coze-studio/backend/infra/contract/promptstore"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/rdb"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/storage"
 	chatmodelImpl "github.com/coze-dev/coze-studio/backend/infra/impl/chatmodel"
 	builtinNL2SQL "github.com/coze-dev/coze-studio/backend/infra/impl/document/nl2sql/builtin"
-	"github.com/coze-dev/coze-studio/backend/infra/impl/document/rerank/rrf"
+	// rrf and httpxenc register themselves with the rerank registry in init().
+	_ "github.com/coze-dev/coze-studio/backend/infra/impl/document/rerank/httpxenc"
+	"github.com/coze-dev/coze-studio/backend/infra/impl/document/rerank/llm"
+	_ "github.com/coze-dev/coze-studio/backend/infra/impl/document/rerank/rrf"
 	sses "github.com/coze-dev/coze-studio/backend/infra/impl/document/searchstore/elasticsearch"
 	ssmilvus "github.com/coze-dev/coze-studio/backend/infra/impl/document/searchstore/milvus"
 	ssoceanbase "github.com/coze-dev/coze-studio/backend/infra/impl/document/searchstore/oceanbase"
+	sspgvector "github.com/coze-dev/coze-studio/backend/infra/impl/document/searchstore/pgvector"
+	ssqdrant "github.com/coze-dev/coze-studio/backend/infra/impl/document/searchstore/qdrant"
 	ssvikingdb "github.com/coze-dev/coze-studio/backend/infra/impl/document/searchstore/vikingdb"
 	arkemb "github.com/coze-dev/coze-studio/backend/infra/impl/embedding/ark"
 	"github.com/coze-dev/coze-studio/backend/infra/impl/embedding/http"
+	"github.com/coze-dev/coze-studio/backend/infra/impl/embedding/sparse/bm25"
+	"github.com/coze-dev/coze-studio/backend/infra/impl/embedding/sparse/splade"
 	"github.com/coze-dev/coze-studio/backend/infra/impl/embedding/wrap"
 	"github.com/coze-dev/coze-studio/backend/infra/impl/eventbus"
 	builtinM2Q "github.com/coze-dev/coze-studio/backend/infra/impl/messages2query/builtin"
+	"github.com/coze-dev/coze-studio/backend/infra/impl/promptstore/dbstore"
+	"github.com/coze-dev/coze-studio/backend/infra/impl/promptstore/filewatch"
 	"github.com/coze-dev/coze-studio/backend/pkg/lang/conv"
 	"github.com/coze-dev/coze-studio/backend/pkg/lang/ptr"
 	"github.com/coze-dev/coze-studio/backend/pkg/logs"
@@ -79,8 +89,22 @@ type ServiceComponents struct {
 	CacheCli      cache.Cmdable
 	OCR           ocr.OCR
 	ParserManager parser.Manager
+
+	// ProfilesPath points at the YAML registry of named embedding + vector
+	// store profiles. Defaults to defaultKnowledgeProfilesPath when empty;
+	// if that default file also doesn't exist, a single "default" profile is
+	// synthesized from the legacy EMBEDDING_TYPE / VECTOR_STORE_TYPE env vars.
+	ProfilesPath string
 }
 
+// messagesToQueryTemplateName / nl2sqlTemplateName are the promptstore
+// template names the M2Q rewriter and NL2SQL translator resolve, matching
+// the file names the process used to read them from once at startup.
+const (
+	messagesToQueryTemplateName = "messages_to_query"
+	nl2sqlTemplateName          = "nl2sql"
+)
+
 func InitService(c *ServiceComponents) (*KnowledgeApplicationService, error) {
 	ctx := context.Background()
 
@@ -109,12 +133,27 @@ func InitService(c *ServiceComponents) (*KnowledgeApplicationService, error) {
 		sManagers = append(sManagers, sses.NewManager(&sses.ManagerConfig{Client: c.ES}))
 	}
 
-	// vector search
-	mgr, err := getVectorStore(ctx)
+	// vector search: each knowledge base is created against a named profile
+	// (embedder + vector store pairing) so different KBs in the same
+	// deployment can use different embedders/dimensions/indexes.
+	profilesPath := c.ProfilesPath
+	if profilesPath == "" {
+		profilesPath = defaultKnowledgeProfilesPath
+	}
+	profiles, defaultProfile, err := loadKnowledgeProfiles(profilesPath)
 	if err != nil {
-		return nil, fmt.Errorf("init vector store failed, err=%w", err)
+		return nil, fmt.Errorf("load knowledge profiles failed, err=%w", err)
+	}
+
+	profileManagers := make(map[string]searchstore.Manager, len(profiles))
+	for name, profile := range profiles {
+		pmgr, err := getVectorStore(ctx, profile)
+		if err != nil {
+			return nil, fmt.Errorf("init vector store for profile %q failed, err=%w", name, err)
+		}
+		profileManagers[name] = pmgr
 	}
-	sManagers = append(sManagers, mgr)
+	sManagers = append(sManagers, profileManagers[defaultProfile])
 
 	root, err := os.Getwd()
 	if err != nil {
@@ -122,16 +161,23 @@ func InitService(c *ServiceComponents) (*KnowledgeApplicationService, error) {
 		root = os.Getenv("PWD")
 	}
 
+	// templateStore resolves the active version of the M2Q/NL2SQL prompt
+	// templates so the rewriter/NL2SQL components can pick up a newly
+	// published or rolled-back version without a restart, instead of the
+	// jinja2 JSON files being read once here and baked in for good.
+	templateStore, err := newPromptTemplateStore(c.DB, map[string]string{
+		messagesToQueryTemplateName: filepath.Join(root, "resources/conf/prompt/messages_to_query_template_jinja2.json"),
+		nl2sqlTemplateName:          filepath.Join(root, "resources/conf/prompt/nl2sql_template_jinja2.json"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("init prompt template store failed, err=%w", err)
+	}
+
 	var rewriter messages2query.MessagesToQuery
 	if rewriterChatModel, _, err := internal.GetBuiltinChatModel(ctx, "M2Q_"); err != nil {
 		return nil, err
 	} else {
-		filePath := filepath.Join(root, "resources/conf/prompt/messages_to_query_template_jinja2.json")
-		rewriterTemplate, err := readJinja2PromptTemplate(filePath)
-		if err != nil {
-			return nil, err
-		}
-		rewriter, err = builtinM2Q.NewMessagesToQuery(ctx, rewriterChatModel, rewriterTemplate)
+		rewriter, err = builtinM2Q.NewMessagesToQuery(ctx, rewriterChatModel, promptstore.Of(templateStore, messagesToQueryTemplateName))
 		if err != nil {
 			return nil, err
 		}
@@ -141,17 +187,17 @@ func InitService(c *ServiceComponents) (*KnowledgeApplicationService, error) {
 	if n2sChatModel, _, err := internal.GetBuiltinChatModel(ctx, "NL2SQL_"); err != nil {
 		return nil, err
 	} else {
-		filePath := filepath.Join(root, "resources/conf/prompt/nl2sql_template_jinja2.json")
-		n2sTemplate, err := readJinja2PromptTemplate(filePath)
-		if err != nil {
-			return nil, err
-		}
-		n2s, err = builtinNL2SQL.NewNL2SQL(ctx, n2sChatModel, n2sTemplate)
+		n2s, err = builtinNL2SQL.NewNL2SQL(ctx, n2sChatModel, promptstore.Of(templateStore, nl2sqlTemplateName))
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	reranker, err := getReranker(ctx, profiles[defaultProfile])
+	if err != nil {
+		return nil, fmt.Errorf("init reranker failed, err=%w", err)
+	}
+
 	knowledgeDomainSVC, knowledgeEventHandler := knowledgeImpl.NewKnowledgeSVC(&knowledgeImpl.KnowledgeSVCConfig{
 		DB:                  c.DB,
 		IDGen:               c.IDGenSVC,
@@ -161,11 +207,19 @@ func InitService(c *ServiceComponents) (*KnowledgeApplicationService, error) {
 		ParseManager:        c.ParserManager,
 		Storage:             c.Storage,
 		Rewriter:            rewriter,
-		Reranker:            rrf.NewRRFReranker(0), // default rrf
+		Reranker:            reranker,
 		NL2Sql:              n2s,
 		OCR:                 c.OCR,
 		CacheCli:            c.CacheCli,
 		ModelFactory:        chatmodelImpl.NewDefaultFactory(),
+		// ProfileManagers/DefaultProfile let the domain service pick the
+		// vector store a given knowledge base was created with (by its
+		// recorded profile name) instead of always using the process-wide
+		// default, and detect a dimension mismatch (via
+		// ProfileDimensionMismatch) when a KB's profile is changed so it can
+		// queue a re-embed through the knowledge event bus.
+		ProfileManagers: profileManagers,
+		DefaultProfile:  defaultProfile,
 	})
 
 	if err = eventbus.DefaultSVC().RegisterConsumer(nameServer, consts.RMQTopicKnowledge, consts.RMQConsumeGroupKnowledge, knowledgeEventHandler); err != nil {
@@ -178,18 +232,27 @@ func InitService(c *ServiceComponents) (*KnowledgeApplicationService, error) {
 	return KnowledgeSVC, nil
 }
 
-func getVectorStore(ctx context.Context) (searchstore.Manager, error) {
-	vsType := os.Getenv("VECTOR_STORE_TYPE")
+// getVectorStore builds the searchstore.Manager for one knowledge profile.
+// vsType/params come from the profile so different profiles in the same
+// deployment can point at different backends/instances, falling back to the
+// legacy VECTOR_STORE_TYPE / *_env vars for whichever params a profile
+// doesn't override.
+func getVectorStore(ctx context.Context, profile *KnowledgeProfile) (searchstore.Manager, error) {
+	vs := profile.VectorStore
+	vsType := vs.Type
+	if vsType == "" {
+		vsType = os.Getenv("VECTOR_STORE_TYPE")
+	}
 
 	switch vsType {
 	case "oceanbase":
 		// 使用OceanBase作为向量存储
-		db, err := getOceanBaseDB()
+		db, err := getOceanBaseDB(vs)
 		if err != nil {
 			return nil, fmt.Errorf("init oceanbase db failed, err=%w", err)
 		}
 
-		emb, err := getEmbedding(ctx)
+		emb, _, err := getEmbedding(ctx, profile)
 		if err != nil {
 			return nil, fmt.Errorf("init oceanbase embedding failed, err=%w", err)
 		}
@@ -207,21 +270,22 @@ func getVectorStore(ctx context.Context) (searchstore.Manager, error) {
 		cctx, cancel := context.WithTimeout(ctx, time.Second*5)
 		defer cancel()
 
-		milvusAddr := os.Getenv("MILVUS_ADDR")
+		milvusAddr := vs.param("addr", "MILVUS_ADDR")
 		mc, err := milvusclient.New(cctx, &milvusclient.ClientConfig{Address: milvusAddr})
 		if err != nil {
 			return nil, fmt.Errorf("init milvus client failed, err=%w", err)
 		}
 
-		emb, err := getEmbedding(ctx)
+		emb, sparseEmb, err := getEmbedding(ctx, profile)
 		if err != nil {
 			return nil, fmt.Errorf("init milvus embedding failed, err=%w", err)
 		}
 
 		mgr, err := ssmilvus.NewManager(&ssmilvus.ManagerConfig{
-			Client:       mc,
-			Embedding:    emb,
-			EnableHybrid: ptr.Of(true),
+			Client:          mc,
+			Embedding:       emb,
+			EnableHybrid:    ptr.Of(sparseEmb != nil),
+			SparseEmbedding: sparseEmb,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("init milvus vector store failed, err=%w", err)
@@ -230,12 +294,12 @@ func getVectorStore(ctx context.Context) (searchstore.Manager, error) {
 		return mgr, nil
 	case "vikingdb":
 		var (
-			host      = os.Getenv("VIKING_DB_HOST")
-			region    = os.Getenv("VIKING_DB_REGION")
-			ak        = os.Getenv("VIKING_DB_AK")
-			sk        = os.Getenv("VIKING_DB_SK")
-			scheme    = os.Getenv("VIKING_DB_SCHEME")
-			modelName = os.Getenv("VIKING_DB_MODEL_NAME")
+			host      = vs.param("host", "VIKING_DB_HOST")
+			region    = vs.param("region", "VIKING_DB_REGION")
+			ak        = vs.param("ak", "VIKING_DB_AK")
+			sk        = vs.param("sk", "VIKING_DB_SK")
+			scheme    = vs.param("scheme", "VIKING_DB_SCHEME")
+			modelName = vs.param("model_name", "VIKING_DB_MODEL_NAME")
 		)
 		if ak == "" || sk == "" {
 			return nil, fmt.Errorf("invalid vikingdb ak / sk")
@@ -265,7 +329,7 @@ func getVectorStore(ctx context.Context) (searchstore.Manager, error) {
 				BuiltinEmbedding:   nil,
 			}
 		} else {
-			builtinEmbedding, err := getEmbedding(ctx)
+			builtinEmbedding, _, err := getEmbedding(ctx, profile)
 			if err != nil {
 				return nil, fmt.Errorf("builtint embedding init failed, err=%w", err)
 			}
@@ -288,14 +352,86 @@ func getVectorStore(ctx context.Context) (searchstore.Manager, error) {
 
 		return mgr, nil
 
+	case "pgvector":
+		db, err := getPgVectorDB(vs)
+		if err != nil {
+			return nil, fmt.Errorf("init pgvector db failed, err=%w", err)
+		}
+
+		emb, _, err := getEmbedding(ctx, profile)
+		if err != nil {
+			return nil, fmt.Errorf("init pgvector embedding failed, err=%w", err)
+		}
+
+		mgr, err := sspgvector.NewManager(&sspgvector.ManagerConfig{
+			DB:        db,
+			Embedding: emb,
+			IndexType: sspgvector.IndexType(vs.param("index_type", "PGVECTOR_INDEX_TYPE")),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("init pgvector vector store failed, err=%w", err)
+		}
+
+		return mgr, nil
+
+	case "qdrant":
+		addr := vs.param("addr", "QDRANT_ADDR")
+		if addr == "" {
+			addr = "localhost:6334"
+		}
+		apiKey := vs.param("api_key", "QDRANT_API_KEY")
+		useTLS, _ := strconv.ParseBool(vs.param("use_tls", "QDRANT_USE_TLS"))
+
+		qc, err := qdrant.NewClient(&qdrant.Config{
+			Host:   addr,
+			APIKey: apiKey,
+			UseTLS: useTLS,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("init qdrant client failed, err=%w", err)
+		}
+
+		emb, sparseEmb, err := getEmbedding(ctx, profile)
+		if err != nil {
+			return nil, fmt.Errorf("init qdrant embedding failed, err=%w", err)
+		}
+
+		enableHybrid, _ := strconv.ParseBool(vs.param("enable_hybrid", "QDRANT_ENABLE_HYBRID"))
+
+		mgr, err := ssqdrant.NewManager(&ssqdrant.ManagerConfig{
+			Client:          qc,
+			Embedding:       emb,
+			EnableHybrid:    enableHybrid,
+			SparseEmbedding: sparseEmb,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("init qdrant vector store failed, err=%w", err)
+		}
+
+		return mgr, nil
+
 	default:
 		return nil, fmt.Errorf("unexpected vector store type, type=%s", vsType)
 	}
 }
 
-func getOceanBaseDB() (*gorm.DB, error) {
-	// 从环境变量获取OceanBase连接信息
-	dsn := os.Getenv("OCEANBASE_DSN")
+func getPgVectorDB(vs ProfileComponent) (*gorm.DB, error) {
+	dsn := vs.param("dsn", "PGVECTOR_DSN")
+	if dsn == "" {
+		dsn = "host=localhost user=postgres password=postgres dbname=opencoze port=5432 sslmode=disable"
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("connect to pgvector failed: %w", err)
+	}
+
+	return db, nil
+}
+
+func getOceanBaseDB(vs ProfileComponent) (*gorm.DB, error) {
+	// 从 profile 或环境变量获取OceanBase连接信息
+	dsn := vs.param("dsn", "OCEANBASE_DSN")
 	if dsn == "" {
 		// 默认连接字符串
 		dsn = "root@test:coze123@tcp(localhost:2881)/opencoze?charset=utf8mb4&parseTime=True&loc=Local"
@@ -309,9 +445,11 @@ func getOceanBaseDB() (*gorm.DB, error) {
 	return db, nil
 }
 
-func getEmbedding(ctx context.Context) (embedding.Embedder, error) {
+func getEmbedding(ctx context.Context, profile *KnowledgeProfile) (embedding.Embedder, embedding.SparseEmbedder, error) {
+	emCfg := profile.Embedding
+
 	var batchSize int
-	if bs, err := strconv.ParseInt(os.Getenv("EMBEDDING_MAX_BATCH_SIZE"), 10, 64); err != nil {
+	if bs, err := strconv.ParseInt(emCfg.param("max_batch_size", "EMBEDDING_MAX_BATCH_SIZE"), 10, 64); err != nil {
 		logs.CtxWarnf(ctx, "EMBEDDING_MAX_BATCH_SIZE not set / invalid, using default batchSize=100")
 		batchSize = 100
 	} else {
@@ -320,26 +458,31 @@ func getEmbedding(ctx context.Context) (embedding.Embedder, error) {
 
 	var emb embedding.Embedder
 
-	switch os.Getenv("EMBEDDING_TYPE") {
+	embType := emCfg.Type
+	if embType == "" {
+		embType = os.Getenv("EMBEDDING_TYPE")
+	}
+
+	switch embType {
 	case "openai":
 		var (
-			openAIEmbeddingBaseURL     = os.Getenv("OPENAI_EMBEDDING_BASE_URL")
-			openAIEmbeddingModel       = os.Getenv("OPENAI_EMBEDDING_MODEL")
-			openAIEmbeddingApiKey      = os.Getenv("OPENAI_EMBEDDING_API_KEY")
-			openAIEmbeddingByAzure     = os.Getenv("OPENAI_EMBEDDING_BY_AZURE")
-			openAIEmbeddingApiVersion  = os.Getenv("OPENAI_EMBEDDING_API_VERSION")
-			openAIEmbeddingDims        = os.Getenv("OPENAI_EMBEDDING_DIMS")
-			openAIRequestEmbeddingDims = os.Getenv("OPENAI_EMBEDDING_REQUEST_DIMS")
+			openAIEmbeddingBaseURL     = emCfg.param("base_url", "OPENAI_EMBEDDING_BASE_URL")
+			openAIEmbeddingModel       = emCfg.param("model", "OPENAI_EMBEDDING_MODEL")
+			openAIEmbeddingApiKey      = emCfg.param("api_key", "OPENAI_EMBEDDING_API_KEY")
+			openAIEmbeddingByAzure     = emCfg.param("by_azure", "OPENAI_EMBEDDING_BY_AZURE")
+			openAIEmbeddingApiVersion  = emCfg.param("api_version", "OPENAI_EMBEDDING_API_VERSION")
+			openAIEmbeddingDims        = emCfg.param("dims", "OPENAI_EMBEDDING_DIMS")
+			openAIRequestEmbeddingDims = emCfg.param("request_dims", "OPENAI_EMBEDDING_REQUEST_DIMS")
 		)
 
 		byAzure, err := strconv.ParseBool(openAIEmbeddingByAzure)
 		if err != nil {
-			return nil, fmt.Errorf("init openai embedding by_azure failed, err=%w", err)
+			return nil, nil, fmt.Errorf("init openai embedding by_azure failed, err=%w", err)
 		}
 
 		dims, err := strconv.ParseInt(openAIEmbeddingDims, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("init openai embedding dims failed, err=%w", err)
+			return nil, nil, fmt.Errorf("init openai embedding dims failed, err=%w", err)
 		}
 
 		openAICfg := &openai.EmbeddingConfig{
@@ -358,30 +501,30 @@ func getEmbedding(ctx context.Context) (embedding.Embedder, error) {
 
 		emb, err = wrap.NewOpenAIEmbedder(ctx, openAICfg, dims, batchSize)
 		if err != nil {
-			return nil, fmt.Errorf("init openai embedding failed, err=%w", err)
+			return nil, nil, fmt.Errorf("init openai embedding failed, err=%w", err)
 		}
 
 	case "ark":
 		var (
-			arkEmbeddingBaseURL = os.Getenv("ARK_EMBEDDING_BASE_URL")
-			arkEmbeddingModel   = os.Getenv("ARK_EMBEDDING_MODEL")
-			arkEmbeddingApiKey  = os.Getenv("ARK_EMBEDDING_API_KEY")
+			arkEmbeddingBaseURL = emCfg.param("base_url", "ARK_EMBEDDING_BASE_URL")
+			arkEmbeddingModel   = emCfg.param("model", "ARK_EMBEDDING_MODEL")
+			arkEmbeddingApiKey  = emCfg.param("api_key", "ARK_EMBEDDING_API_KEY")
 			// deprecated: use ARK_EMBEDDING_API_KEY instead
 			// ARK_EMBEDDING_AK will be removed in the future
-			arkEmbeddingAK      = os.Getenv("ARK_EMBEDDING_AK")
-			arkEmbeddingDims    = os.Getenv("ARK_EMBEDDING_DIMS")
-			arkEmbeddingAPIType = os.Getenv("ARK_EMBEDDING_API_TYPE")
+			arkEmbeddingAK      = emCfg.param("ak", "ARK_EMBEDDING_AK")
+			arkEmbeddingDims    = emCfg.param("dims", "ARK_EMBEDDING_DIMS")
+			arkEmbeddingAPIType = emCfg.param("api_type", "ARK_EMBEDDING_API_TYPE")
 		)
 
 		dims, err := strconv.ParseInt(arkEmbeddingDims, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("init ark embedding dims failed, err=%w", err)
+			return nil, nil, fmt.Errorf("init ark embedding dims failed, err=%w", err)
 		}
 
 		apiType := ark.APITypeText
 		if arkEmbeddingAPIType != "" {
 			if t := ark.APIType(arkEmbeddingAPIType); t != ark.APITypeText && t != ark.APITypeMultiModal {
-				return nil, fmt.Errorf("init ark embedding api_type failed, invalid api_type=%s", t)
+				return nil, nil, fmt.Errorf("init ark embedding api_type failed, invalid api_type=%s", t)
 			} else {
 				apiType = t
 			}
@@ -399,19 +542,19 @@ func getEmbedding(ctx context.Context) (embedding.Embedder, error) {
 			APIType: &apiType,
 		}, dims, batchSize)
 		if err != nil {
-			return nil, fmt.Errorf("init ark embedding client failed, err=%w", err)
+			return nil, nil, fmt.Errorf("init ark embedding client failed, err=%w", err)
 		}
 
 	case "ollama":
 		var (
-			ollamaEmbeddingBaseURL = os.Getenv("OLLAMA_EMBEDDING_BASE_URL")
-			ollamaEmbeddingModel   = os.Getenv("OLLAMA_EMBEDDING_MODEL")
-			ollamaEmbeddingDims    = os.Getenv("OLLAMA_EMBEDDING_DIMS")
+			ollamaEmbeddingBaseURL = emCfg.param("base_url", "OLLAMA_EMBEDDING_BASE_URL")
+			ollamaEmbeddingModel   = emCfg.param("model", "OLLAMA_EMBEDDING_MODEL")
+			ollamaEmbeddingDims    = emCfg.param("dims", "OLLAMA_EMBEDDING_DIMS")
 		)
 
 		dims, err := strconv.ParseInt(ollamaEmbeddingDims, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("init ollama embedding dims failed, err=%w", err)
+			return nil, nil, fmt.Errorf("init ollama embedding dims failed, err=%w", err)
 		}
 
 		emb, err = wrap.NewOllamaEmbedder(ctx, &ollamaEmb.EmbeddingConfig{
@@ -419,42 +562,108 @@ func getEmbedding(ctx context.Context) (embedding.Embedder, error) {
 			Model:   ollamaEmbeddingModel,
 		}, dims, batchSize)
 		if err != nil {
-			return nil, fmt.Errorf("init ollama embedding failed, err=%w", err)
+			return nil, nil, fmt.Errorf("init ollama embedding failed, err=%w", err)
 		}
 
 	case "http":
 		var (
-			httpEmbeddingBaseURL = os.Getenv("HTTP_EMBEDDING_ADDR")
-			httpEmbeddingDims    = os.Getenv("HTTP_EMBEDDING_DIMS")
+			httpEmbeddingBaseURL = emCfg.param("addr", "HTTP_EMBEDDING_ADDR")
+			httpEmbeddingDims    = emCfg.param("dims", "HTTP_EMBEDDING_DIMS")
 		)
 		dims, err := strconv.ParseInt(httpEmbeddingDims, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("init http embedding dims failed, err=%w", err)
+			return nil, nil, fmt.Errorf("init http embedding dims failed, err=%w", err)
 		}
 		emb, err = http.NewEmbedding(httpEmbeddingBaseURL, dims, batchSize)
 		if err != nil {
-			return nil, fmt.Errorf("init http embedding failed, err=%w", err)
+			return nil, nil, fmt.Errorf("init http embedding failed, err=%w", err)
 		}
 
 	default:
-		return nil, fmt.Errorf("init knowledge embedding failed, type not configured")
+		return nil, nil, fmt.Errorf("init knowledge embedding failed, type not configured")
+	}
+
+	sparseEmb, err := getSparseEmbedding(emCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("init knowledge sparse embedding failed, err=%w", err)
 	}
 
-	return emb, nil
+	return emb, sparseEmb, nil
 }
 
-func readJinja2PromptTemplate(jsonFilePath string) (prompt.ChatTemplate, error) {
-	b, err := os.ReadFile(jsonFilePath)
-	if err != nil {
-		return nil, err
+// getSparseEmbedding builds the optional embedding.SparseEmbedder a profile
+// configures for hybrid retrieval (Milvus/Qdrant managers fuse it with the
+// dense leg at query time). Returns (nil, nil) when the profile configures
+// neither a sparse type nor SPARSE_EMBEDDING_TYPE, so hybrid stays off by
+// default exactly like before this existed.
+func getSparseEmbedding(emCfg ProfileComponent) (embedding.SparseEmbedder, error) {
+	sparseType := emCfg.param("sparse_type", "SPARSE_EMBEDDING_TYPE")
+
+	switch sparseType {
+	case "":
+		return nil, nil
+
+	case "splade":
+		baseURL := emCfg.param("sparse_addr", "SPLADE_ADDR")
+		apiKey := emCfg.param("sparse_api_key", "SPLADE_API_KEY")
+		return splade.NewEmbedder(splade.Config{BaseURL: baseURL, APIKey: apiKey})
+
+	case "bm25":
+		return bm25.NewEmbedder(bm25.Config{}), nil
+
+	default:
+		return nil, fmt.Errorf("sparse embedding type not supported, type=%s", sparseType)
 	}
-	var m2qMessages []*schema.Message
-	if err = json.Unmarshal(b, &m2qMessages); err != nil {
-		return nil, err
+}
+
+// getReranker builds the reranker for one knowledge profile from the
+// registered rerank.Factory matching profile.Reranker.Type, falling back to
+// env var RERANKER_TYPE and finally to RRF when neither is set, so existing
+// deployments keep their current fusion behavior unchanged.
+//
+// The "llm" type is handled separately from the registry because it needs a
+// live chat model instance (built the same way InitService already builds
+// the M2Q / NL2SQL models) rather than a config of plain string params.
+func getReranker(ctx context.Context, profile *KnowledgeProfile) (rerank.Reranker, error) {
+	rrCfg := profile.Reranker
+	rrType := rrCfg.Type
+	if rrType == "" {
+		rrType = os.Getenv("RERANKER_TYPE")
+	}
+	if rrType == "" {
+		rrType = "rrf"
 	}
-	tpl := make([]schema.MessagesTemplate, len(m2qMessages))
-	for i := range m2qMessages {
-		tpl[i] = m2qMessages[i]
+
+	if rrType == "llm" {
+		cm, _, err := internal.GetBuiltinChatModel(ctx, "RERANK_")
+		if err != nil {
+			return nil, fmt.Errorf("init reranker chat model failed, err=%w", err)
+		}
+		return llm.NewLLMReranker(&llm.Config{ChatModel: cm})
+	}
+
+	factory, ok := rerank.Get(rrType)
+	if !ok {
+		return nil, fmt.Errorf("unexpected reranker type, type=%s", rrType)
+	}
+
+	weight := 0.0
+	if w, err := strconv.ParseFloat(rrCfg.Params["weight"], 64); err == nil {
+		weight = w
+	}
+
+	return factory.New(rerank.Config{Type: rrType, Params: rrCfg.Params, Weight: weight})
+}
+
+// newPromptTemplateStore builds the promptstore.TemplateStore the M2Q/NL2SQL
+// components resolve their active prompt template from. It defaults to the
+// gorm-backed dbstore, seeding each name's "0.0.0" version from bootstrapPaths
+// the first time that name has no rows; PROMPT_TEMPLATE_STORE=file switches
+// to the filewatch store instead, for deployments that mount templates from
+// a ConfigMap/secret volume rather than managing them through the database.
+func newPromptTemplateStore(db *gorm.DB, bootstrapPaths map[string]string) (promptstore.TemplateStore, error) {
+	if os.Getenv("PROMPT_TEMPLATE_STORE") == "file" {
+		return filewatch.NewStore(filewatch.Config{Paths: bootstrapPaths})
 	}
-	return prompt.FromMessages(schema.Jinja2, tpl...), nil
+	return dbstore.NewStore(dbstore.Config{DB: db, BootstrapPaths: bootstrapPaths})
 }
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultKnowledgeProfilesPath is where InitService looks for the profile
+// registry by default; ServiceComponents.ProfilesPath overrides it.
+const defaultKnowledgeProfilesPath = "resources/conf/knowledge/profiles.yaml"
+
+// defaultProfileName is used both as the registry key for the env-var-derived
+// fallback profile, and as the profile a knowledge base is assumed to belong
+// to when it predates per-KB profile tracking.
+const defaultProfileName = "default"
+
+// ProfileComponent is a generic type + param bag for one embedder or vector
+// store configuration. Using a param map instead of one struct field per
+// provider lets every existing *_TYPE env var (openai/ark/ollama/http for
+// embedding; oceanbase/milvus/vikingdb for vector store) carry over into YAML
+// unchanged, under Params, instead of redefining a dedicated struct per
+// provider.
+type ProfileComponent struct {
+	Type   string            `yaml:"type"`
+	Params map[string]string `yaml:"params"`
+}
+
+// param returns Params[key] if the profile set it, otherwise falls back to
+// the env var a single-profile deployment would have used, so a profile only
+// needs to override the settings that actually differ from the environment.
+func (pc ProfileComponent) param(key, fallbackEnv string) string {
+	if pc.Params != nil {
+		if v, ok := pc.Params[key]; ok {
+			return v
+		}
+	}
+	return os.Getenv(fallbackEnv)
+}
+
+// KnowledgeProfile names a paired embedder + vector-store configuration that
+// one or more knowledge bases are created against. Each knowledge base
+// records which profile it used (outside this package, in the knowledge
+// domain's persisted KB record) so retrieval, ingestion, and reindexing keep
+// using the embedder/index it was built with, even after the registry's
+// default profile changes.
+type KnowledgeProfile struct {
+	Name        string           `yaml:"name"`
+	Embedding   ProfileComponent `yaml:"embedding"`
+	VectorStore ProfileComponent `yaml:"vector_store"`
+
+	// Reranker selects the reranker this profile's retrieval results are
+	// fused through: type rrf or http_cross_encoder names a registered
+	// rerank.Factory (see infra/contract/document/rerank), and type llm is
+	// built by getReranker from a chat model instead. A "weight"
+	// param lets a profile blend a reranker's score against the raw
+	// retriever score instead of fully replacing it, so corpora can be
+	// A/B'd without recompiling.
+	Reranker ProfileComponent `yaml:"reranker"`
+}
+
+// knowledgeProfilesFile is the on-disk shape of the YAML registry.
+type knowledgeProfilesFile struct {
+	DefaultProfile string              `yaml:"default_profile"`
+	Profiles       []*KnowledgeProfile `yaml:"profiles"`
+}
+
+// loadKnowledgeProfiles reads the named profile registry. When path does not
+// exist, it returns a single synthetic "default" profile built purely from
+// the legacy EMBEDDING_TYPE / VECTOR_STORE_TYPE env vars, so a deployment
+// that never adopted the YAML registry keeps working unchanged.
+func loadKnowledgeProfiles(path string) (map[string]*KnowledgeProfile, string, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		fallback := &KnowledgeProfile{Name: defaultProfileName}
+		return map[string]*KnowledgeProfile{defaultProfileName: fallback}, defaultProfileName, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("read knowledge profiles file failed, path=%s, err=%w", path, err)
+	}
+
+	var f knowledgeProfilesFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, "", fmt.Errorf("parse knowledge profiles file failed, path=%s, err=%w", path, err)
+	}
+	if len(f.Profiles) == 0 {
+		return nil, "", fmt.Errorf("knowledge profiles file has no profiles, path=%s", path)
+	}
+
+	profiles := make(map[string]*KnowledgeProfile, len(f.Profiles))
+	for _, p := range f.Profiles {
+		if p.Name == "" {
+			return nil, "", fmt.Errorf("knowledge profile missing a name, path=%s", path)
+		}
+		profiles[p.Name] = p
+	}
+
+	defaultProfile := f.DefaultProfile
+	if defaultProfile == "" {
+		defaultProfile = f.Profiles[0].Name
+	}
+	if _, ok := profiles[defaultProfile]; !ok {
+		return nil, "", fmt.Errorf("default_profile %q is not a known profile, path=%s", defaultProfile, path)
+	}
+
+	return profiles, defaultProfile, nil
+}
+
+// ProfileDimensionMismatch reports whether switching a knowledge base from
+// oldProfile to newProfile changes the embedding dimensionality, which means
+// its existing vectors are no longer comparable to newly embedded ones and
+// it needs a full re-embed rather than an in-place index swap.
+func ProfileDimensionMismatch(oldProfile, newProfile *KnowledgeProfile) bool {
+	if oldProfile == nil || newProfile == nil {
+		return false
+	}
+	// Resolve dims the way getEmbedding does: the profile's type (or
+	// EMBEDDING_TYPE) picks the upper-case <TYPE>_EMBEDDING_DIMS env fallback.
+	dims := func(p *KnowledgeProfile) string {
+		embType := p.Embedding.Type
+		if embType == "" {
+			embType = os.Getenv("EMBEDDING_TYPE")
+		}
+		return p.Embedding.param("dims", strings.ToUpper(embType)+"_EMBEDDING_DIMS")
+	}
+	return dims(oldProfile) != dims(newProfile)
+}
+
+// reembedTopic is the knowledgeProducer topic a queued re-embed job is
+// published on; the knowledge domain service's consumer (registered next to
+// knowledgeProducer in InitService) is expected to handle it by re-running
+// ingestion for the named knowledge base against newProfileName.
+const reembedTopic = "knowledge_reembed"
+
+// ReembedEvent is the message body queued onto knowledgeProducer when a
+// knowledge base's profile change requires a full re-embed rather than an
+// in-place swap.
+type ReembedEvent struct {
+	KnowledgeID    string `json:"knowledge_id"`
+	OldProfileName string `json:"old_profile_name"`
+	NewProfileName string `json:"new_profile_name"`
+}
+
+// ReembedProducer is the minimal slice of knowledgeProducer (see
+// InitService) that queuing a re-embed job needs, so this package doesn't
+// have to depend on the eventbus producer's concrete type.
+type ReembedProducer interface {
+	Send(ctx context.Context, topic string, body []byte) error
+}
+
+// ApplyProfileChange is what the knowledge domain service's update-profile
+// flow calls when a knowledge base is switched from oldProfileName to
+// newProfileName: it resolves both names against the registry, and if the
+// switch changes embedding dimensionality, publishes a ReembedEvent on
+// producer before returning. The caller persists recordProfileName onto the
+// knowledge base's own record as the profile it's now built against,
+// because that record lives in the knowledge domain, not in this package.
+func ApplyProfileChange(ctx context.Context, profiles map[string]*KnowledgeProfile, producer ReembedProducer, knowledgeID, oldProfileName, newProfileName string) (recordProfileName string, err error) {
+	newProfile, ok := profiles[newProfileName]
+	if !ok {
+		return "", fmt.Errorf("unknown knowledge profile, name=%s", newProfileName)
+	}
+
+	oldProfile := profiles[oldProfileName]
+	if ProfileDimensionMismatch(oldProfile, newProfile) {
+		event := ReembedEvent{KnowledgeID: knowledgeID, OldProfileName: oldProfileName, NewProfileName: newProfileName}
+		body, err := json.Marshal(event)
+		if err != nil {
+			return "", fmt.Errorf("marshal reembed event failed, knowledge_id=%s, err=%w", knowledgeID, err)
+		}
+		if err := producer.Send(ctx, reembedTopic, body); err != nil {
+			return "", fmt.Errorf("queue reembed event failed, knowledge_id=%s, err=%w", knowledgeID, err)
+		}
+	}
+
+	return newProfileName, nil
+}
@@ -0,0 +1,222 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec turns an arbitrary Go value into bytes a Client can store, and back.
+// Backends that can't otherwise represent a value (anything but []byte or
+// string, see toBytes in the OceanBase implementation) fall back to whatever
+// Codec the client is configured with, instead of silently dropping it.
+type Codec interface {
+	Encode(v any) ([]byte, error)
+	Decode(data []byte, out any) error
+}
+
+// CodecID identifies which Codec produced an envelope's payload (see
+// EncodeEnvelope/DecodeEnvelope below), so a value can be decoded correctly
+// even after a deployment switches its configured default codec.
+type CodecID byte
+
+const (
+	CodecJSON    CodecID = 1
+	CodecMsgpack CodecID = 2
+	CodecProto   CodecID = 3
+)
+
+// JSONCodec is the default Codec: universally readable, slower and larger on
+// the wire than Msgpack or Proto, appropriate as the safe fallback.
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (JSONCodec) Decode(data []byte, out any) error { return json.Unmarshal(data, out) }
+
+// MsgpackCodec trades JSON's cross-language readability for a denser binary
+// encoding; pick it when a key is hot enough that encode/decode cost and
+// payload size matter more than being able to read the raw value by eye.
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) Encode(v any) ([]byte, error) { return msgpack.Marshal(v) }
+
+func (MsgpackCodec) Decode(data []byte, out any) error { return msgpack.Unmarshal(data, out) }
+
+// ProtoCodec encodes proto.Message values with their own wire format. It
+// only works for values that are already proto.Message - there's no generic
+// way to turn an arbitrary struct into one, so Encode/Decode reject anything
+// else rather than silently falling back to another codec.
+type ProtoCodec struct{}
+
+func (ProtoCodec) Encode(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("cache: ProtoCodec requires a proto.Message, got %T", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (ProtoCodec) Decode(data []byte, out any) error {
+	msg, ok := out.(proto.Message)
+	if !ok {
+		return fmt.Errorf("cache: ProtoCodec requires a proto.Message, got %T", out)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+// codecsByID lets DecodeEnvelope/Client.GetObject resolve the codec that
+// produced a stored value from the ID tagged onto its envelope, without the
+// caller having to already know (or keep in sync) which codec is currently
+// configured for writes.
+var codecsByID = map[CodecID]Codec{
+	CodecJSON:    JSONCodec{},
+	CodecMsgpack: MsgpackCodec{},
+	CodecProto:   ProtoCodec{},
+}
+
+// CodecByID looks up one of the built-in codecs by the ID an envelope was
+// tagged with.
+func CodecByID(id CodecID) (Codec, error) {
+	codec, ok := codecsByID[id]
+	if !ok {
+		return nil, fmt.Errorf("cache: unknown codec id %d", id)
+	}
+	return codec, nil
+}
+
+// CompressionID identifies which (if any) compression algorithm was applied
+// to an envelope's payload on top of the codec encoding.
+type CompressionID byte
+
+const (
+	CompressionNone   CompressionID = 0
+	CompressionSnappy CompressionID = 1
+	CompressionZstd   CompressionID = 2
+)
+
+const (
+	// envelopeMagic tags a stored value as a codec envelope rather than a
+	// raw []byte/string, so DecodeEnvelope can reject anything else instead
+	// of misreading it.
+	envelopeMagic byte = 0xC5
+	// envelopeHeaderLen is the fixed [magic:1][codec:1][flags:1][origLen:2]
+	// header every envelope is prefixed with.
+	envelopeHeaderLen = 5
+	// maxEnvelopePayload is the largest pre-compression payload origLen (a
+	// uint16) can record.
+	maxEnvelopePayload = 0xFFFF
+)
+
+// EncodeEnvelope encodes v with codec, compressing the result with
+// compression when it's at least threshold bytes and doing so actually
+// shrinks it, and wraps it in the fixed 5-byte TLV header described above.
+func EncodeEnvelope(codecID CodecID, codec Codec, compression CompressionID, threshold int, v any) ([]byte, error) {
+	payload, err := codec.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > maxEnvelopePayload {
+		return nil, fmt.Errorf("cache: encoded value is %d bytes, over the %d-byte envelope header limit", len(payload), maxEnvelopePayload)
+	}
+	origLen := len(payload)
+
+	flags := CompressionNone
+	if compression != CompressionNone && origLen >= threshold {
+		compressed, err := compressPayload(compression, payload)
+		if err == nil && len(compressed) < len(payload) {
+			payload = compressed
+			flags = compression
+		}
+	}
+
+	header := []byte{envelopeMagic, byte(codecID), byte(flags), byte(origLen >> 8), byte(origLen)}
+	return append(header, payload...), nil
+}
+
+// DecodeEnvelope strips the TLV header off data, decompressing the payload
+// if the header's flags say it was compressed, and returns the codec ID the
+// payload still needs to be run through.
+func DecodeEnvelope(data []byte) (codecID CodecID, payload []byte, err error) {
+	if len(data) < envelopeHeaderLen || data[0] != envelopeMagic {
+		return 0, nil, fmt.Errorf("cache: value is not a codec envelope")
+	}
+
+	codecID = CodecID(data[1])
+	flags := CompressionID(data[2])
+	origLen := int(data[3])<<8 | int(data[4])
+	payload = data[envelopeHeaderLen:]
+
+	if flags != CompressionNone {
+		payload, err = decompressPayload(flags, payload, origLen)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	return codecID, payload, nil
+}
+
+func compressPayload(algo CompressionID, data []byte) ([]byte, error) {
+	switch algo {
+	case CompressionSnappy:
+		return snappy.Encode(nil, data), nil
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer enc.Close()
+		return enc.EncodeAll(data, nil), nil
+	default:
+		return data, nil
+	}
+}
+
+func decompressPayload(algo CompressionID, data []byte, origLen int) ([]byte, error) {
+	switch algo {
+	case CompressionSnappy:
+		return snappy.Decode(nil, data)
+	case CompressionZstd:
+		dec, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		out := make([]byte, 0, origLen)
+		buf := make([]byte, origLen)
+		for {
+			n, err := dec.Read(buf)
+			out = append(out, buf[:n]...)
+			if err == io.EOF {
+				return out, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return data, nil
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Factory builds a Cmdable backend, given the shared *gorm.DB handle; a
+// backend that doesn't need it (e.g. Redis) is free to ignore db. Backends
+// register their Factory under a stable name from their own package's
+// init(), so callers like appinfra.Init can select one by name (typically
+// from an env var) without importing every backend package directly.
+type Factory func(db *gorm.DB) (Cmdable, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{}
+)
+
+// RegisterFactory registers a cache backend constructor under name. It
+// panics on a duplicate name, since that can only be two backends colliding
+// at init time, not a runtime condition callers should have to handle.
+func RegisterFactory(name string, f Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if _, ok := factories[name]; ok {
+		panic(fmt.Sprintf("cache: factory %q already registered", name))
+	}
+	factories[name] = f
+}
+
+// NewCmdable builds the backend registered under name via its Factory.
+func NewCmdable(name string, db *gorm.DB) (Cmdable, error) {
+	factoriesMu.RLock()
+	f, ok := factories[name]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("cache: no factory registered for backend %q", name)
+	}
+	return f(db)
+}
@@ -0,0 +1,285 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Pinger is implemented by a Cmdable backend that can report its own health
+// more cheaply than exercising a real command. WithHealthCheck calls Ping if
+// the primary backend implements it, and otherwise falls back to a
+// lightweight Exists probe against cfg.ProbeKey.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthCheckConfig tunes WithHealthCheck's probe loop.
+type HealthCheckConfig struct {
+	// Interval between health probes. Defaults to 5s if zero.
+	Interval time.Duration
+	// FailureThreshold is how many consecutive failed probes it takes to
+	// fail over to Secondary. Defaults to 3 if zero.
+	FailureThreshold int
+	// ProbeKey is read via Exists when Primary doesn't implement Pinger.
+	// Defaults to "__cache_healthcheck__" if empty.
+	ProbeKey string
+}
+
+// WithHealthCheck wraps primary with a background probe loop that fails
+// over every call to secondary after cfg.FailureThreshold consecutive
+// failed probes, and fails back once primary reports healthy again.
+// secondary may be nil, in which case a failed primary is still served
+// (today's behavior) rather than every call panicking on a nil backend.
+//
+// The returned Cmdable is safe for concurrent use; Close stops the probe
+// goroutine and should be called once the returned Cmdable is no longer
+// needed.
+func WithHealthCheck(primary, secondary Cmdable, cfg HealthCheckConfig) Cmdable {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 3
+	}
+	if cfg.ProbeKey == "" {
+		cfg.ProbeKey = "__cache_healthcheck__"
+	}
+
+	f := &failoverCmdable{primary: primary, secondary: secondary, cfg: cfg, stop: make(chan struct{})}
+	f.active.Store(&primary)
+	go f.run()
+	return f
+}
+
+type failoverCmdable struct {
+	primary   Cmdable
+	secondary Cmdable
+	cfg       HealthCheckConfig
+
+	active   atomic.Value // *Cmdable, the backend currently serving calls
+	failures int32
+
+	stop     chan struct{}
+	stopOnce int32
+}
+
+func (f *failoverCmdable) cur() Cmdable {
+	return *f.active.Load().(*Cmdable)
+}
+
+// Close stops the background probe loop. It does not close primary or
+// secondary themselves, since WithHealthCheck doesn't own their lifecycle.
+func (f *failoverCmdable) Close() {
+	if atomic.CompareAndSwapInt32(&f.stopOnce, 0, 1) {
+		close(f.stop)
+	}
+}
+
+func (f *failoverCmdable) run() {
+	ticker := time.NewTicker(f.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.probe()
+		case <-f.stop:
+			return
+		}
+	}
+}
+
+func (f *failoverCmdable) probe() {
+	ctx, cancel := context.WithTimeout(context.Background(), f.cfg.Interval)
+	defer cancel()
+
+	if f.ping(ctx) == nil {
+		atomic.StoreInt32(&f.failures, 0)
+		if f.secondary != nil {
+			f.active.Store(&f.primary)
+		}
+		return
+	}
+
+	if f.secondary != nil && int(atomic.AddInt32(&f.failures, 1)) >= f.cfg.FailureThreshold {
+		f.active.Store(&f.secondary)
+	}
+}
+
+func (f *failoverCmdable) ping(ctx context.Context) error {
+	if p, ok := f.primary.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return f.primary.Exists(ctx, f.cfg.ProbeKey).Err()
+}
+
+// The rest of this file is a straight pass-through of every Cmdable method
+// onto whichever backend is currently active, so WithHealthCheck's result
+// is itself a drop-in Cmdable.
+
+func (f *failoverCmdable) Pipeline() Pipeliner { return f.cur().Pipeline() }
+
+func (f *failoverCmdable) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) StatusCmd {
+	return f.cur().Set(ctx, key, value, expiration)
+}
+
+func (f *failoverCmdable) Get(ctx context.Context, key string) StringCmd {
+	return f.cur().Get(ctx, key)
+}
+
+func (f *failoverCmdable) IncrBy(ctx context.Context, key string, value int64) IntCmd {
+	return f.cur().IncrBy(ctx, key, value)
+}
+
+func (f *failoverCmdable) DecrBy(ctx context.Context, key string, value int64) IntCmd {
+	return f.cur().DecrBy(ctx, key, value)
+}
+
+func (f *failoverCmdable) Incr(ctx context.Context, key string) IntCmd {
+	return f.cur().Incr(ctx, key)
+}
+
+func (f *failoverCmdable) Decr(ctx context.Context, key string) IntCmd {
+	return f.cur().Decr(ctx, key)
+}
+
+func (f *failoverCmdable) IncrByFloat(ctx context.Context, key string, value float64) FloatCmd {
+	return f.cur().IncrByFloat(ctx, key, value)
+}
+
+func (f *failoverCmdable) HIncrBy(ctx context.Context, key string, field string, value int64) IntCmd {
+	return f.cur().HIncrBy(ctx, key, field, value)
+}
+
+func (f *failoverCmdable) HSet(ctx context.Context, key string, values ...interface{}) IntCmd {
+	return f.cur().HSet(ctx, key, values...)
+}
+
+func (f *failoverCmdable) HGetAll(ctx context.Context, key string) MapStringStringCmd {
+	return f.cur().HGetAll(ctx, key)
+}
+
+func (f *failoverCmdable) Del(ctx context.Context, keys ...string) IntCmd {
+	return f.cur().Del(ctx, keys...)
+}
+
+func (f *failoverCmdable) Exists(ctx context.Context, keys ...string) IntCmd {
+	return f.cur().Exists(ctx, keys...)
+}
+
+func (f *failoverCmdable) Expire(ctx context.Context, key string, expiration time.Duration) BoolCmd {
+	return f.cur().Expire(ctx, key, expiration)
+}
+
+func (f *failoverCmdable) LPush(ctx context.Context, key string, values ...interface{}) IntCmd {
+	return f.cur().LPush(ctx, key, values...)
+}
+
+func (f *failoverCmdable) RPush(ctx context.Context, key string, values ...interface{}) IntCmd {
+	return f.cur().RPush(ctx, key, values...)
+}
+
+func (f *failoverCmdable) LIndex(ctx context.Context, key string, index int64) StringCmd {
+	return f.cur().LIndex(ctx, key, index)
+}
+
+func (f *failoverCmdable) LSet(ctx context.Context, key string, index int64, value interface{}) StatusCmd {
+	return f.cur().LSet(ctx, key, index, value)
+}
+
+func (f *failoverCmdable) LPop(ctx context.Context, key string) StringCmd {
+	return f.cur().LPop(ctx, key)
+}
+
+func (f *failoverCmdable) LRange(ctx context.Context, key string, start, stop int64) StringSliceCmd {
+	return f.cur().LRange(ctx, key, start, stop)
+}
+
+func (f *failoverCmdable) Publish(ctx context.Context, channel string, payload any) IntCmd {
+	return f.cur().Publish(ctx, channel, payload)
+}
+
+func (f *failoverCmdable) Subscribe(ctx context.Context, channels ...string) PubSub {
+	return f.cur().Subscribe(ctx, channels...)
+}
+
+func (f *failoverCmdable) PSubscribe(ctx context.Context, patterns ...string) PubSub {
+	return f.cur().PSubscribe(ctx, patterns...)
+}
+
+func (f *failoverCmdable) SAdd(ctx context.Context, key string, members ...interface{}) IntCmd {
+	return f.cur().SAdd(ctx, key, members...)
+}
+
+func (f *failoverCmdable) SRem(ctx context.Context, key string, members ...interface{}) IntCmd {
+	return f.cur().SRem(ctx, key, members...)
+}
+
+func (f *failoverCmdable) SMembers(ctx context.Context, key string) StringSliceCmd {
+	return f.cur().SMembers(ctx, key)
+}
+
+func (f *failoverCmdable) SIsMember(ctx context.Context, key string, member interface{}) BoolCmd {
+	return f.cur().SIsMember(ctx, key, member)
+}
+
+func (f *failoverCmdable) SCard(ctx context.Context, key string) IntCmd {
+	return f.cur().SCard(ctx, key)
+}
+
+func (f *failoverCmdable) SInter(ctx context.Context, keys ...string) StringSliceCmd {
+	return f.cur().SInter(ctx, keys...)
+}
+
+func (f *failoverCmdable) SUnion(ctx context.Context, keys ...string) StringSliceCmd {
+	return f.cur().SUnion(ctx, keys...)
+}
+
+func (f *failoverCmdable) ZAdd(ctx context.Context, key string, members ...Z) IntCmd {
+	return f.cur().ZAdd(ctx, key, members...)
+}
+
+func (f *failoverCmdable) ZRem(ctx context.Context, key string, members ...interface{}) IntCmd {
+	return f.cur().ZRem(ctx, key, members...)
+}
+
+func (f *failoverCmdable) ZCard(ctx context.Context, key string) IntCmd {
+	return f.cur().ZCard(ctx, key)
+}
+
+func (f *failoverCmdable) ZScore(ctx context.Context, key string, member string) FloatCmd {
+	return f.cur().ZScore(ctx, key, member)
+}
+
+func (f *failoverCmdable) ZIncrBy(ctx context.Context, key string, increment float64, member string) FloatCmd {
+	return f.cur().ZIncrBy(ctx, key, increment, member)
+}
+
+func (f *failoverCmdable) ZRank(ctx context.Context, key string, member string) IntCmd {
+	return f.cur().ZRank(ctx, key, member)
+}
+
+func (f *failoverCmdable) ZRange(ctx context.Context, key string, start, stop int64) StringSliceCmd {
+	return f.cur().ZRange(ctx, key, start, stop)
+}
+
+func (f *failoverCmdable) ZRangeByScore(ctx context.Context, key string, min, max float64) StringSliceCmd {
+	return f.cur().ZRangeByScore(ctx, key, min, max)
+}
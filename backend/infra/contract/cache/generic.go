@@ -17,6 +17,7 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"time"
 )
@@ -32,6 +33,12 @@ type Client interface {
 	Set(key string, value any, expire time.Duration) error
 	GetBytes(key string) ([]byte, error)
 	GetString(key string) (string, error)
+	// GetObject decodes the value stored at key into out using the Codec it
+	// was written with (see codec.go), the same codec regardless of what a
+	// Client is currently configured to encode new values with. It errors if
+	// key's value was never run through a codec, i.e. Set stored it as a
+	// plain []byte/string.
+	GetObject(key string, out any) error
 	Delete(key string) (int64, error)
 	Count(key ...string) (int64, error)
 	SetMapField(key string, field string, value string) error
@@ -44,6 +51,79 @@ type Client interface {
 	Transaction(fn func(context Context) error) error
 	Publish(channel string, message string) error
 	Subscribe(channel string) (<-chan string, func())
+	// SubscribeDurable subscribes under a caller-chosen, stable subscriberID.
+	// Unlike Subscribe, which hands out a fresh ephemeral subscriber on every
+	// call and therefore always starts at the tail, SubscribeDurable resumes
+	// from the subscriber's last acknowledged message if one is already
+	// recorded in the backing store, so a reconnecting consumer does not miss
+	// messages published while it was disconnected.
+	SubscribeDurable(channel string, subscriberID string) (<-chan string, func())
+
+	// Pipeline returns a batch accumulator: calls queue onto it instead of
+	// hitting the backing store, and Exec flushes everything queued so far in
+	// as few round trips as the backend can manage.
+	Pipeline() Pipeline
+	// MGet reads many keys in one round trip. Keys with no value (expired or
+	// absent) are simply missing from the returned map.
+	MGet(keys []string) (map[string][]byte, error)
+	// MSet writes many key/value/expiry tuples in one round trip.
+	MSet(kv map[string]CacheEntry) error
+
+	// Lock acquires the distributed lock named key for owner, valid for ttl.
+	// It succeeds if key carries no lock, the existing lock has expired, or
+	// owner already holds it (re-entrant). It reports false, nil - not an
+	// error - when someone else currently holds an unexpired lock.
+	Lock(key string, owner string, ttl time.Duration) (bool, error)
+	// Unlock releases key, but only if owner currently holds it; releasing a
+	// lock this owner doesn't hold is a no-op (false, nil).
+	Unlock(key string, owner string) (bool, error)
+	// RenewLock extends owner's lock on key to ttl from now. It reports
+	// false, nil if owner doesn't currently hold the lock.
+	RenewLock(key string, owner string, ttl time.Duration) (bool, error)
+}
+
+// CacheEntry is one key's value and expiry, as used by MSet.
+type CacheEntry struct {
+	Value  any
+	Expire time.Duration
+}
+
+// Z is one member/score pair, as used by ZAdd. It mirrors go-redis's redis.Z
+// so backends built against the go-redis API port over without renaming.
+type Z struct {
+	Score  float64
+	Member any
+}
+
+// PipelineResult holds the outcome of one operation queued on a Pipeline. It
+// is populated in place once Exec returns, in the order the operation was
+// queued, mirroring how go-redis Cmds are populated after Pipeline.Exec.
+type PipelineResult struct {
+	Bytes []byte
+	Str   string
+	Bool  bool
+	Err   error
+}
+
+// Pipeline batches Client operations so they execute in as few round trips
+// as the backend can manage instead of one round trip per call. Every queue
+// method returns the *PipelineResult that Exec will later fill in.
+type Pipeline interface {
+	Set(key string, value any, expire time.Duration) *PipelineResult
+	Get(key string) *PipelineResult
+	Delete(key string) *PipelineResult
+	// SetNX queues a set-if-absent. Its *PipelineResult.Bool reports whether
+	// this op is the one that set key, like the non-pipelined SetNX; the
+	// OceanBase pipeline also reports a win when key already held a
+	// byte-identical value (see its flushSetNX).
+	SetNX(key string, value any, expire time.Duration) *PipelineResult
+	SetMapField(key string, field string, value string) *PipelineResult
+	GetMapField(key string, field string) *PipelineResult
+	Expire(key string, expire time.Duration) *PipelineResult
+	// Exec flushes every queued operation, populating each one's
+	// *PipelineResult. It returns the first unexpected (non per-op) error
+	// encountered while flushing, e.g. a failure to open the transaction.
+	Exec(ctx context.Context) error
 }
 
 var (
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/coze-dev/coze-studio/backend/pkg/logs"
+)
+
+// ErrLockNotAcquired is returned by WithLock when key is already held by
+// someone else.
+var ErrLockNotAcquired = errors.New("cache: lock not acquired")
+
+// lockOwnerSeq makes every WithLock call's owner token unique within this
+// process even if two calls land in the same nanosecond.
+var lockOwnerSeq atomic.Uint64
+
+// WithLock runs fn while holding the distributed lock key on client, Redlock
+// style whether client is backed by Redis or OceanBase. It acquires the lock
+// with ttl, auto-renews at ttl/3 in the background for as long as fn is
+// running, and cancels the context passed to fn (not this call's return)
+// if a renewal ever fails - fn should treat ctx.Done() as "I may no longer
+// hold the lock" and stop touching whatever the lock protects.
+//
+// WithLock returns ErrLockNotAcquired without calling fn if the lock is
+// already held by someone else.
+func WithLock(ctx context.Context, client Client, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	owner := fmt.Sprintf("%d-%d", time.Now().UnixNano(), lockOwnerSeq.Add(1))
+
+	ok, err := client.Lock(key, owner, ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotAcquired
+	}
+	defer func() {
+		if _, err := client.Unlock(key, owner); err != nil {
+			logs.CtxErrorf(ctx, "cache: failed to unlock %q: %v", key, err)
+		}
+	}()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go renewLoop(runCtx, cancel, client, key, owner, ttl, done)
+
+	return fn(runCtx)
+}
+
+// renewLoop renews key every ttl/3 until done is closed (WithLock's fn
+// returned) or a renewal fails/reports the lock is no longer held, in which
+// case it cancels runCtx so fn's context observably dies instead of silently
+// continuing to run without the lock.
+func renewLoop(runCtx context.Context, cancel context.CancelFunc, client Client, key, owner string, ttl time.Duration, done <-chan struct{}) {
+	interval := ttl / 3
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-runCtx.Done():
+			return
+		case <-ticker.C:
+			ok, err := client.RenewLock(key, owner, ttl)
+			if err != nil {
+				logs.CtxErrorf(runCtx, "cache: failed to renew lock %q: %v", key, err)
+				cancel()
+				return
+			}
+			if !ok {
+				logs.CtxErrorf(runCtx, "cache: lost lock %q during renewal", key)
+				cancel()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import "context"
+
+// PubSubMessage is one message delivered to a subscriber, mirroring
+// go-redis's *redis.Message so callers migrating off go-redis keep the same
+// shape.
+type PubSubMessage struct {
+	Channel string // the channel the message was published on
+	Pattern string // the pattern that matched, set only for PSubscribe
+	Payload string
+}
+
+// PubSub is a subscription handle, mirroring go-redis's *redis.PubSub.
+type PubSub interface {
+	// Channel returns a channel delivering every message received for the
+	// lifetime of the subscription. It is closed when Close is called.
+	Channel() <-chan *PubSubMessage
+	// ReceiveMessage blocks for the next message, or returns ctx.Err() if ctx
+	// is done first.
+	ReceiveMessage(ctx context.Context) (*PubSubMessage, error)
+	Close() error
+}
+
+// PubSubCmdable is implemented by backends that support channel-based
+// pub/sub, alongside the lower-level Client.Publish/Subscribe primitives.
+type PubSubCmdable interface {
+	Publish(ctx context.Context, channel string, payload any) IntCmd
+	Subscribe(ctx context.Context, channels ...string) PubSub
+	PSubscribe(ctx context.Context, patterns ...string) PubSub
+}
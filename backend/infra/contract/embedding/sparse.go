@@ -0,0 +1,36 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package embedding
+
+import "context"
+
+// SparseVector is a sparse (lexical-ish) embedding: parallel Indices/Values
+// slices, one weight per active dimension. Unlike the dense vectors Embedder
+// produces, most dimensions are zero and are simply omitted.
+type SparseVector struct {
+	Indices []uint32
+	Values  []float32
+}
+
+// SparseEmbedder produces sparse embeddings alongside the dense ones
+// Embedder produces, for backends that fuse a dense leg with a sparse leg
+// (SPLADE, BM25 term weights, ...) at the store level instead of blending
+// dense similarity with a separately-computed text match. Implementations
+// are expected to return one SparseVector per input text, in order.
+type SparseEmbedder interface {
+	EmbedStringsSparse(ctx context.Context, texts []string) ([]SparseVector, error)
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package promptstore abstracts where a named prompt template's active
+// version lives, so builtin components like the M2Q rewriter and NL2SQL
+// translator can re-resolve it on every request instead of baking in
+// whatever resources/conf/prompt/*.json held at process start.
+package promptstore
+
+import "context"
+
+// Template is one published version of a named prompt template: the raw
+// []*schema.Message JSON the legacy resources/conf/prompt/*.json files use,
+// tagged with the semver it was published under.
+type Template struct {
+	Name     string
+	Version  string
+	Messages []byte
+}
+
+// TemplateStore resolves the active version of a named template, and lets
+// operators publish or roll back versions without a process restart.
+// Implementations are free to back this however they like (the gorm-backed
+// dbstore.Store versions rows in a table; filewatch.Store tracks a mounted
+// file's contents); callers that need to pick up new versions mid-process
+// should call GetActive per request rather than caching its result.
+type TemplateStore interface {
+	// GetActive returns the named template's currently active version.
+	GetActive(ctx context.Context, name string) (*Template, error)
+	// CreateVersion publishes a new version of the named template and makes
+	// it active immediately.
+	CreateVersion(ctx context.Context, name, version string, messages []byte) error
+	// Rollback makes a previously published version of the named template
+	// active again.
+	Rollback(ctx context.Context, name, version string) error
+	// ListVersions returns every published version of the named template,
+	// most recently created first.
+	ListVersions(ctx context.Context, name string) ([]*Template, error)
+	// Watch registers onChange to be called whenever the named template's
+	// active version changes, for callers that would rather be pushed an
+	// update than call GetActive on every request. The returned cancel func
+	// stops delivery; implementations with nothing to push may return a
+	// no-op cancel.
+	Watch(ctx context.Context, name string, onChange func(*Template)) (cancel func(), err error)
+}
+
+// Resolver returns the named template's currently active version, the same
+// way TemplateStore.GetActive does, bound to one name. Components that need
+// to re-resolve a single template per request (rather than holding a
+// TemplateStore and a name) take a Resolver instead.
+type Resolver func(ctx context.Context) (*Template, error)
+
+// Of binds a TemplateStore to one template name, for components that only
+// ever resolve a single template and don't need the rest of the
+// TemplateStore surface.
+func Of(store TemplateStore, name string) Resolver {
+	return func(ctx context.Context) (*Template, error) {
+		return store.GetActive(ctx, name)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lru
+
+import "fmt"
+
+// toString renders a stored value the way Get reports it, mirroring how a
+// real backend round-trips a non-string Set value back out as a string.
+func toString(v interface{}) string {
+	return fmt.Sprintf("%v", v)
+}
+
+type statusCmd struct{ err error }
+
+func (s *statusCmd) Err() error              { return s.err }
+func (s *statusCmd) Result() (string, error) { return "", s.err }
+
+type stringCmd struct {
+	val string
+	err error
+}
+
+func (s *stringCmd) Err() error              { return s.err }
+func (s *stringCmd) Result() (string, error) { return s.val, s.err }
+func (s *stringCmd) Val() string             { return s.val }
+func (s *stringCmd) Int64() (int64, error)   { return 0, s.err }
+func (s *stringCmd) Bytes() ([]byte, error)  { return []byte(s.val), s.err }
+
+type intCmd struct {
+	val int64
+	err error
+}
+
+func (i *intCmd) Err() error             { return i.err }
+func (i *intCmd) Result() (int64, error) { return i.val, i.err }
+
+type floatCmd struct {
+	val float64
+	err error
+}
+
+func (f *floatCmd) Err() error               { return f.err }
+func (f *floatCmd) Result() (float64, error) { return f.val, f.err }
+
+type boolCmd struct {
+	val bool
+	err error
+}
+
+func (b *boolCmd) Err() error            { return b.err }
+func (b *boolCmd) Result() (bool, error) { return b.val, b.err }
+
+type mapStringStringCmd struct {
+	val map[string]string
+	err error
+}
+
+func (m *mapStringStringCmd) Err() error                         { return m.err }
+func (m *mapStringStringCmd) Result() (map[string]string, error) { return m.val, m.err }
+
+type stringSliceCmd struct {
+	val []string
+	err error
+}
+
+func (s *stringSliceCmd) Err() error                { return s.err }
+func (s *stringSliceCmd) Result() ([]string, error) { return s.val, s.err }
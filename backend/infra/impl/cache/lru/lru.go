@@ -0,0 +1,284 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package lru implements a bounded, in-process cache.Cmdable meant to be
+// passed as the secondary backend to cache.WithHealthCheck: a last-resort
+// fallback for when the real cache backend is unreachable, not a general
+// purpose cache of its own. It starts empty and is not a live mirror of the
+// primary's data, so only keys written to it (or re-populated on
+// read-through by the caller) while it is serving are servable from it.
+// WithHealthCheck does not clear it on fail-back, so entries written during
+// one failover are still present, until they expire or are evicted, at the
+// next one.
+// Hash/list/set/sorted-set/pub-sub commands are out of scope for this
+// fallback; they return cache.ErrNotFound or a zero value rather than
+// silently behaving like a real backend.
+package lru
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+)
+
+const defaultCapacity = 10000
+
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time // zero means no expiry
+}
+
+// Cmdable is a bounded LRU cache.Cmdable. The zero value is not usable; build
+// one with New.
+type Cmdable struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+// New builds an LRU-backed cache.Cmdable holding at most capacity entries.
+// A non-positive capacity falls back to defaultCapacity.
+func New(capacity int) *Cmdable {
+	if capacity <= 0 {
+		capacity = defaultCapacity
+	}
+	return &Cmdable{capacity: capacity, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Ping always succeeds: the LRU lives in-process, so there is nothing to
+// actually probe. It exists so Cmdable satisfies cache.Pinger cheaply rather
+// than being probed via Exists like a real backend.
+func (c *Cmdable) Ping(ctx context.Context) error { return nil }
+
+func (c *Cmdable) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+func (c *Cmdable) set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*entry).value = value
+		el.Value.(*entry).expires = expires
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	el := c.ll.PushFront(&entry{key: key, value: value, expires: expires})
+	c.items[key] = el
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*entry).key)
+		}
+	}
+}
+
+func (c *Cmdable) del(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.ll.Remove(el)
+	delete(c.items, key)
+	return true
+}
+
+func (c *Cmdable) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.StatusCmd {
+	c.set(key, value, expiration)
+	return &statusCmd{}
+}
+
+func (c *Cmdable) Get(ctx context.Context, key string) cache.StringCmd {
+	v, ok := c.get(key)
+	if !ok {
+		return &stringCmd{err: cache.ErrNotFound}
+	}
+	if s, ok := v.(string); ok {
+		return &stringCmd{val: s}
+	}
+	return &stringCmd{val: toString(v)}
+}
+
+func (c *Cmdable) Del(ctx context.Context, keys ...string) cache.IntCmd {
+	var n int64
+	for _, key := range keys {
+		if c.del(key) {
+			n++
+		}
+	}
+	return &intCmd{val: n}
+}
+
+func (c *Cmdable) Exists(ctx context.Context, keys ...string) cache.IntCmd {
+	var n int64
+	for _, key := range keys {
+		if _, ok := c.get(key); ok {
+			n++
+		}
+	}
+	return &intCmd{val: n}
+}
+
+func (c *Cmdable) Expire(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	v, ok := c.get(key)
+	if !ok {
+		return &boolCmd{}
+	}
+	c.set(key, v, expiration)
+	return &boolCmd{val: true}
+}
+
+func (c *Cmdable) incrBy(key string, delta int64) (int64, error) {
+	v, _ := c.get(key)
+	n, _ := v.(int64)
+	n += delta
+	c.set(key, n, 0)
+	return n, nil
+}
+
+func (c *Cmdable) Incr(ctx context.Context, key string) cache.IntCmd { return c.IncrBy(ctx, key, 1) }
+func (c *Cmdable) Decr(ctx context.Context, key string) cache.IntCmd { return c.IncrBy(ctx, key, -1) }
+func (c *Cmdable) DecrBy(ctx context.Context, key string, value int64) cache.IntCmd {
+	return c.IncrBy(ctx, key, -value)
+}
+
+func (c *Cmdable) IncrBy(ctx context.Context, key string, value int64) cache.IntCmd {
+	n, err := c.incrBy(key, value)
+	return &intCmd{val: n, err: err}
+}
+
+func (c *Cmdable) IncrByFloat(ctx context.Context, key string, value float64) cache.FloatCmd {
+	v, _ := c.get(key)
+	f, _ := v.(float64)
+	f += value
+	c.set(key, f, 0)
+	return &floatCmd{val: f}
+}
+
+// Pipeline, Hash/List/Set/ZSet commands, and Pub/Sub are out of scope for
+// this fallback - see the package doc comment. The commands return
+// cache.ErrNotFound; Pipeline, Subscribe and PSubscribe return nil.
+
+func (c *Cmdable) Pipeline() cache.Pipeliner { return nil }
+
+func (c *Cmdable) HIncrBy(ctx context.Context, key string, field string, value int64) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) HSet(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) HGetAll(ctx context.Context, key string) cache.MapStringStringCmd {
+	return &mapStringStringCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) LPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) RPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) LIndex(ctx context.Context, key string, index int64) cache.StringCmd {
+	return &stringCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) LSet(ctx context.Context, key string, index int64, value interface{}) cache.StatusCmd {
+	return &statusCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) LPop(ctx context.Context, key string) cache.StringCmd {
+	return &stringCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) LRange(ctx context.Context, key string, start, stop int64) cache.StringSliceCmd {
+	return &stringSliceCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) Publish(ctx context.Context, channel string, payload any) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) Subscribe(ctx context.Context, channels ...string) cache.PubSub  { return nil }
+func (c *Cmdable) PSubscribe(ctx context.Context, patterns ...string) cache.PubSub { return nil }
+func (c *Cmdable) SAdd(ctx context.Context, key string, members ...interface{}) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) SRem(ctx context.Context, key string, members ...interface{}) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) SMembers(ctx context.Context, key string) cache.StringSliceCmd {
+	return &stringSliceCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) SIsMember(ctx context.Context, key string, member interface{}) cache.BoolCmd {
+	return &boolCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) SCard(ctx context.Context, key string) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) SInter(ctx context.Context, keys ...string) cache.StringSliceCmd {
+	return &stringSliceCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) SUnion(ctx context.Context, keys ...string) cache.StringSliceCmd {
+	return &stringSliceCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) ZAdd(ctx context.Context, key string, members ...cache.Z) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) ZRem(ctx context.Context, key string, members ...interface{}) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) ZCard(ctx context.Context, key string) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) ZScore(ctx context.Context, key string, member string) cache.FloatCmd {
+	return &floatCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) ZIncrBy(ctx context.Context, key string, increment float64, member string) cache.FloatCmd {
+	return &floatCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) ZRank(ctx context.Context, key string, member string) cache.IntCmd {
+	return &intCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) ZRange(ctx context.Context, key string, start, stop int64) cache.StringSliceCmd {
+	return &stringSliceCmd{err: cache.ErrNotFound}
+}
+func (c *Cmdable) ZRangeByScore(ctx context.Context, key string, min, max float64) cache.StringSliceCmd {
+	return &stringSliceCmd{err: cache.ErrNotFound}
+}
@@ -18,9 +18,12 @@ package oceanbase
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"time"
 
+	"gorm.io/gorm"
+
 	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
 )
 
@@ -36,8 +39,7 @@ func NewCmdableAdapter(client cache.Client) cache.Cmdable {
 
 // Pipeline 实现cache.Cmdable接口
 func (c *CmdableAdapter) Pipeline() cache.Pipeliner {
-	// OceanBase不支持管道操作，返回一个空实现
-	return &PipelinerAdapter{}
+	return newPipelinerAdapter(c.client)
 }
 
 // Set 实现StringCmdable接口
@@ -52,47 +54,112 @@ func (c *CmdableAdapter) Get(ctx context.Context, key string) cache.StringCmd {
 	return &StringCmdAdapter{val: val, err: err}
 }
 
-// IncrBy 实现StringCmdable接口
+// noExpirySentinel is used as expire_time for a key created with no TTL, so
+// the "expire_time > NOW()" freshness check in reads never treats it as
+// stale. A plain zero/NULL would either compare as already-expired or
+// require every read path to special-case it.
+const noExpirySentinel = 100 * 365 * 24 * time.Hour
+
+// IncrBy 实现StringCmdable接口，使用原子 UPSERT 而不是读-改-写，
+// 避免并发调用在 OceanBase 默认隔离级别下出现"各自读到旧值、其中一次写入丢失"的竞态。
 func (c *CmdableAdapter) IncrBy(ctx context.Context, key string, value int64) cache.IntCmd {
-	// 使用数据库事务实现原子递增
-	var result int64
-	err := c.client.Transaction(func(context cache.Context) error {
-		// 获取当前值
-		currentVal, err := context.Get().GetString(key)
-		if err != nil && err != cache.ErrNotFound {
+	result, err := atomicIncrBy(clientDB(c.client), key, strconv.FormatInt(value, 10), "SIGNED")
+	if err != nil {
+		return &IntCmdAdapter{err: err}
+	}
+	n, err := strconv.ParseInt(result, 10, 64)
+	return &IntCmdAdapter{val: n, err: err}
+}
+
+// DecrBy 实现StringCmdable接口
+func (c *CmdableAdapter) DecrBy(ctx context.Context, key string, value int64) cache.IntCmd {
+	return c.IncrBy(ctx, key, -value)
+}
+
+// Incr 实现StringCmdable接口
+func (c *CmdableAdapter) Incr(ctx context.Context, key string) cache.IntCmd {
+	// 使用IncrBy实现Incr
+	return c.IncrBy(ctx, key, 1)
+}
+
+// Decr 实现StringCmdable接口
+func (c *CmdableAdapter) Decr(ctx context.Context, key string) cache.IntCmd {
+	return c.IncrBy(ctx, key, -1)
+}
+
+// IncrByFloat 实现StringCmdable接口，使用原子 UPSERT
+func (c *CmdableAdapter) IncrByFloat(ctx context.Context, key string, value float64) cache.FloatCmd {
+	result, err := atomicIncrBy(clientDB(c.client), key, strconv.FormatFloat(value, 'f', -1, 64), "DECIMAL(65,20)")
+	if err != nil {
+		return &FloatCmdAdapter{err: err}
+	}
+	f, err := strconv.ParseFloat(result, 64)
+	return &FloatCmdAdapter{val: f, err: err}
+}
+
+// atomicIncrBy 通过一条 INSERT ... ON DUPLICATE KEY UPDATE 原子地把 cache_kvs.cache_value
+// 当作数值递增，再在同一事务内 SELECT 出新值返回，避免读-改-写竞态。castType决定中间
+// 计算用的数值类型：IncrBy/Incr/DecrBy传"SIGNED"，因为CAST(...AS DECIMAL(65,20))再
+// CAST回CHAR会打印完整的定点小数（比如"5.00000000000000000000"），而不是
+// strconv.ParseInt能读的整数；IncrByFloat则需要DECIMAL才能保留小数部分。
+func atomicIncrBy(db *gorm.DB, key string, delta string, castType string) (string, error) {
+	var result string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		sql := fmt.Sprintf(`INSERT INTO cache_kvs (cache_key, cache_value, expire_time, created_at, updated_at)
+			VALUES (?, ?, ?, NOW(), NOW())
+			ON DUPLICATE KEY UPDATE
+			cache_value = CAST(CAST(cache_value AS %s) + CAST(? AS %s) AS CHAR),
+			updated_at = NOW()`, castType, castType)
+
+		if err := tx.Exec(sql, key, []byte(delta), time.Now().Add(noExpirySentinel), delta).Error; err != nil {
 			return err
 		}
 
-		var current int64
-		if err == cache.ErrNotFound {
-			current = 0
-		} else {
-			current, err = strconv.ParseInt(currentVal, 10, 64)
-			if err != nil {
-				return err
-			}
+		var row CacheKV
+		if err := tx.Where("cache_key = ?", key).First(&row).Error; err != nil {
+			return err
 		}
+		result = string(row.CacheValue)
+		return nil
+	})
+
+	return result, err
+}
+
+// HIncrBy 实现HashCmdable接口，使用原子 UPSERT 递增哈希字段
+func (c *CmdableAdapter) HIncrBy(ctx context.Context, key string, field string, value int64) cache.IntCmd {
+	db := clientDB(c.client)
 
-		// 计算新值
-		result = current + value
+	var result int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		sql := `INSERT INTO cache_maps (cache_key, cache_field, cache_value, created_at, updated_at)
+			VALUES (?, ?, ?, NOW(), NOW())
+			ON DUPLICATE KEY UPDATE
+			cache_value = CAST(CAST(cache_value AS SIGNED) + ? AS CHAR),
+			updated_at = NOW()`
+
+		if err := tx.Exec(sql, key, field, strconv.FormatInt(value, 10), value).Error; err != nil {
+			return err
+		}
 
-		// 设置新值
-		return context.Get().Set(key, strconv.FormatInt(result, 10), 0)
+		var row CacheMap
+		if err := tx.Where("cache_key = ? AND cache_field = ?", key, field).First(&row).Error; err != nil {
+			return err
+		}
+		n, err := strconv.ParseInt(row.CacheValue, 10, 64)
+		if err != nil {
+			return err
+		}
+		result = n
+		return nil
 	})
 
 	if err != nil {
 		return &IntCmdAdapter{err: err}
 	}
-
 	return &IntCmdAdapter{val: result}
 }
 
-// Incr 实现StringCmdable接口
-func (c *CmdableAdapter) Incr(ctx context.Context, key string) cache.IntCmd {
-	// 使用IncrBy实现Incr
-	return c.IncrBy(ctx, key, 1)
-}
-
 // HSet 实现HashCmdable接口
 func (c *CmdableAdapter) HSet(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
 	// 将values转换为map字段
@@ -152,41 +219,7 @@ func (c *CmdableAdapter) Expire(ctx context.Context, key string, expiration time
 	return &BoolCmdAdapter{val: success, err: err}
 }
 
-// LIndex 实现ListCmdable接口
-func (c *CmdableAdapter) LIndex(ctx context.Context, key string, index int64) cache.StringCmd {
-	// OceanBase不支持列表操作，返回错误
-	return &StringCmdAdapter{err: cache.ErrNotFound}
-}
-
-// LPush 实现ListCmdable接口
-func (c *CmdableAdapter) LPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
-	// OceanBase不支持列表操作，返回错误
-	return &IntCmdAdapter{err: cache.ErrNotFound}
-}
-
-// RPush 实现ListCmdable接口
-func (c *CmdableAdapter) RPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
-	// OceanBase不支持列表操作，返回错误
-	return &IntCmdAdapter{err: cache.ErrNotFound}
-}
-
-// LSet 实现ListCmdable接口
-func (c *CmdableAdapter) LSet(ctx context.Context, key string, index int64, value interface{}) cache.StatusCmd {
-	// OceanBase不支持列表操作，返回错误
-	return &StatusCmdAdapter{err: cache.ErrNotFound}
-}
-
-// LPop 实现ListCmdable接口
-func (c *CmdableAdapter) LPop(ctx context.Context, key string) cache.StringCmd {
-	// OceanBase不支持列表操作，返回错误
-	return &StringCmdAdapter{err: cache.ErrNotFound}
-}
-
-// LRange 实现ListCmdable接口
-func (c *CmdableAdapter) LRange(ctx context.Context, key string, start, stop int64) cache.StringSliceCmd {
-	// OceanBase不支持列表操作，返回错误
-	return &StringSliceCmdAdapter{err: cache.ErrNotFound}
-}
+// List/Set/ZSet 命令的实现见 lists.go、sets.go、zsets.go
 
 // 适配器实现各种Cmd接口
 type StatusCmdAdapter struct {
@@ -239,6 +272,19 @@ func (i *IntCmdAdapter) Result() (int64, error) {
 	return i.val, i.err
 }
 
+type FloatCmdAdapter struct {
+	val float64
+	err error
+}
+
+func (f *FloatCmdAdapter) Err() error {
+	return f.err
+}
+
+func (f *FloatCmdAdapter) Result() (float64, error) {
+	return f.val, f.err
+}
+
 type BoolCmdAdapter struct {
 	val bool
 	err error
@@ -278,52 +324,8 @@ func (s *StringSliceCmdAdapter) Result() ([]string, error) {
 	return s.val, s.err
 }
 
-type PipelinerAdapter struct{}
-
-func (p *PipelinerAdapter) Pipeline() cache.Pipeliner {
-	return p
-}
-
-func (p *PipelinerAdapter) Exec(ctx context.Context) ([]cache.Cmder, error) {
-	return nil, cache.ErrNotFound
-}
-
-func (p *PipelinerAdapter) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.StatusCmd {
-	return &StatusCmdAdapter{err: cache.ErrNotFound}
-}
-
-func (p *PipelinerAdapter) Get(ctx context.Context, key string) cache.StringCmd {
-	return &StringCmdAdapter{err: cache.ErrNotFound}
-}
-
-func (p *PipelinerAdapter) IncrBy(ctx context.Context, key string, value int64) cache.IntCmd {
-	return &IntCmdAdapter{err: cache.ErrNotFound}
-}
-
-func (p *PipelinerAdapter) Incr(ctx context.Context, key string) cache.IntCmd {
-	return &IntCmdAdapter{err: cache.ErrNotFound}
-}
-
-func (p *PipelinerAdapter) HSet(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
-	return &IntCmdAdapter{err: cache.ErrNotFound}
-}
-
-func (p *PipelinerAdapter) HGetAll(ctx context.Context, key string) cache.MapStringStringCmd {
-	return &MapStringStringCmdAdapter{err: cache.ErrNotFound}
-}
-
-func (p *PipelinerAdapter) Del(ctx context.Context, keys ...string) cache.IntCmd {
-	return &IntCmdAdapter{err: cache.ErrNotFound}
-}
-
-func (p *PipelinerAdapter) Exists(ctx context.Context, keys ...string) cache.IntCmd {
-	return &IntCmdAdapter{err: cache.ErrNotFound}
-}
-
-func (p *PipelinerAdapter) Expire(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
-	return &BoolCmdAdapter{err: cache.ErrNotFound}
-}
-
+// PipelinerAdapter 的实现见 pipeliner.go。list 命令的管道化批处理暂不支持，
+// 实现见下方对应方法。
 func (p *PipelinerAdapter) LIndex(ctx context.Context, key string, index int64) cache.StringCmd {
 	return &StringCmdAdapter{err: cache.ErrNotFound}
 }
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import "sync"
+
+// localBroker wakes same-process subscribers the instant Publish inserts a
+// row, instead of making them wait out the rest of their poll interval.
+// OceanBase exposes no row-change notification stream this tree can hook
+// into, so this is not a real LISTEN/NOTIFY: a subscriber living in a
+// different process only ever finds out about a new message on its next
+// poll tick. localBroker just removes that latency for the common case
+// where publisher and subscriber share a process, with polling kept as the
+// cross-process (and only) fallback otherwise.
+type localBroker struct {
+	mu   sync.Mutex
+	subs map[string][]chan struct{}
+}
+
+var broker = &localBroker{subs: make(map[string][]chan struct{})}
+
+// subscribe registers a wake channel for channel. The returned cancel func
+// unregisters it and closes it; callers must range over the channel (not
+// read once) so they observe that close instead of blocking forever.
+func (b *localBroker) subscribe(channel string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	b.subs[channel] = append(b.subs[channel], ch)
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			list := b.subs[channel]
+			for i, c := range list {
+				if c == ch {
+					b.subs[channel] = append(list[:i], list[i+1:]...)
+					break
+				}
+			}
+			if len(b.subs[channel]) == 0 {
+				delete(b.subs, channel)
+			}
+			close(ch)
+		})
+	}
+
+	return ch, cancel
+}
+
+// notify wakes every subscriber currently registered for channel. It never
+// blocks: a subscriber that hasn't drained its previous wake yet just
+// coalesces this one into it.
+func (b *localBroker) notify(channel string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ch := range b.subs[channel] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// mergeWakes fans in the wake channels for several channel names into one,
+// so a single Subscribe call covering multiple channels can still select on
+// just one case. The returned cancel unregisters and stops draining all of
+// them.
+func mergeWakes(channels []string) (<-chan struct{}, func()) {
+	merged := make(chan struct{}, 1)
+	cancels := make([]func(), 0, len(channels))
+	var wg sync.WaitGroup
+
+	for _, channel := range channels {
+		wake, cancel := broker.subscribe(channel)
+		cancels = append(cancels, cancel)
+
+		wg.Add(1)
+		go func(wake <-chan struct{}) {
+			defer wg.Done()
+			for range wake {
+				select {
+				case merged <- struct{}{}:
+				default:
+				}
+			}
+		}(wake)
+	}
+
+	return merged, func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+		wg.Wait()
+	}
+}
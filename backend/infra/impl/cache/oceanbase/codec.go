@@ -0,0 +1,113 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"fmt"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+)
+
+// encodingRaw and encodingEnveloped are the values CacheKV.Encoding takes.
+// Every row written before this column existed defaults to encodingRaw, so
+// GetBytes keeps returning exactly the bytes it always did for them.
+const (
+	encodingRaw       byte = 0
+	encodingEnveloped byte = 1
+)
+
+// defaultCompressThreshold is only consulted when an option enables
+// compression; 1MiB mirrors the OceanBase search advisor's style of picking
+// one conservative constant rather than exposing a knob nobody will tune.
+const defaultCompressThreshold = 1 << 20
+
+// codecConfig is a Client's (and its Pipeline's) settings for encoding
+// values Set can't already represent as []byte/string. It is copied by
+// value, never mutated after InitOceanBaseClient builds it, so sharing it
+// between a Client and the Context/Pipeline it hands out needs no locking.
+type codecConfig struct {
+	codecID           cache.CodecID
+	codec             cache.Codec
+	compression       cache.CompressionID
+	compressThreshold int
+}
+
+// defaultCodecConfig is what InitOceanBaseClient uses absent any
+// ClientOption: JSON, uncompressed, so the default behavior for a brand-new
+// deployment is the most cross-language-readable and the least surprising.
+func defaultCodecConfig() codecConfig {
+	return codecConfig{
+		codecID:           cache.CodecJSON,
+		codec:             cache.JSONCodec{},
+		compression:       cache.CompressionNone,
+		compressThreshold: defaultCompressThreshold,
+	}
+}
+
+// ClientOption configures InitOceanBaseClient/InitOceanBaseCache.
+type ClientOption func(*codecConfig)
+
+// WithCodec selects which cache.Codec Set uses to encode values it can't
+// already store as []byte/string, and tags stored envelopes with id so they
+// can be decoded correctly even if a later deployment switches codecs.
+func WithCodec(id cache.CodecID, codec cache.Codec) ClientOption {
+	return func(cfg *codecConfig) {
+		cfg.codecID = id
+		cfg.codec = codec
+	}
+}
+
+// WithCompression enables compressing envelope payloads of at least
+// threshold bytes with algo, when doing so actually shrinks them.
+func WithCompression(algo cache.CompressionID, threshold int) ClientOption {
+	return func(cfg *codecConfig) {
+		cfg.compression = algo
+		cfg.compressThreshold = threshold
+	}
+}
+
+// encodeValue turns v into what should be written to cache_kvs.cache_value,
+// and the CacheKV.Encoding tag that says how to read it back. []byte and
+// string pass through unchanged, exactly as toBytes always did, so existing
+// callers and existing rows are unaffected; anything else is run through
+// codec's configured cache.Codec and wrapped in a TLV envelope instead of
+// toBytes's old silent nil.
+func encodeValue(codec codecConfig, v any) ([]byte, byte, error) {
+	switch val := v.(type) {
+	case []byte:
+		return val, encodingRaw, nil
+	case string:
+		return []byte(val), encodingRaw, nil
+	default:
+		enveloped, err := cache.EncodeEnvelope(codec.codecID, codec.codec, codec.compression, codec.compressThreshold, v)
+		if err != nil {
+			return nil, 0, fmt.Errorf("cache: encoding %T for storage: %w", v, err)
+		}
+		return enveloped, encodingEnveloped, nil
+	}
+}
+
+// decodeStoredValue undoes encodeValue's envelope wrapping (if any),
+// returning the bytes GetBytes/pipeline Get should hand back, plus the
+// CodecID needed to decode them into a typed value (0, encodingRaw's zero
+// value, for rows that were never enveloped).
+func decodeStoredValue(value []byte, encoding byte) (codecID cache.CodecID, payload []byte, err error) {
+	if encoding == encodingRaw {
+		return 0, value, nil
+	}
+	return cache.DecodeEnvelope(value)
+}
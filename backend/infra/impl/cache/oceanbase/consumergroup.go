@@ -0,0 +1,256 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/pkg/logs"
+)
+
+const (
+	groupPollInterval = 200 * time.Millisecond
+	groupPollJitter   = 100 * time.Millisecond
+
+	// groupBatchSize bounds how many messages one poll claims or reclaims at
+	// once, the same way pubSub polling and the expiry sweeper cap their
+	// per-tick work.
+	groupBatchSize = 50
+
+	// groupVisibilityTimeout is how long a claimed-but-unacked message stays
+	// out of circulation before another consumer in the same group is
+	// allowed to reclaim it, mirroring Redis Streams' consumer group
+	// visibility timeout.
+	groupVisibilityTimeout = 30 * time.Second
+)
+
+// GroupMessage is one message delivered to a consumer group. Consumer must
+// call Ack once it has finished processing ID, or another consumer in the
+// same group will reclaim and redeliver it after groupVisibilityTimeout.
+type GroupMessage struct {
+	ID      int64
+	Channel string
+	Message string
+}
+
+// GroupSubscription is a consumer's view of a channel's consumer group,
+// returned by Client.SubscribeGroup. Unlike Subscribe/SubscribeDurable,
+// multiple consumers in the same group split a channel's messages between
+// them instead of each seeing every message.
+type GroupSubscription struct {
+	db       *gorm.DB
+	channel  string
+	group    string
+	consumer string
+	ch       chan *GroupMessage
+	cancel   context.CancelFunc
+}
+
+// SubscribeGroup joins consumer group group on channel as consumer. Every
+// message published to channel is delivered to exactly one consumer in the
+// group (first via an INSERT IGNORE claim row on cache_message_group_claims,
+// see claimNew; reclaims use SELECT ... FOR UPDATE SKIP LOCKED, see
+// reclaimExpired), and is redelivered to another consumer in the group if its claimant never
+// Acks it within groupVisibilityTimeout.
+func (c Client) SubscribeGroup(channel, group, consumer string) *GroupSubscription {
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &GroupSubscription{
+		db:       c.DB,
+		channel:  channel,
+		group:    group,
+		consumer: consumer,
+		ch:       make(chan *GroupMessage, 100),
+		cancel:   cancel,
+	}
+	go sub.loop(ctx)
+	return sub
+}
+
+// Messages returns the channel GroupSubscription delivers claimed messages
+// on. It is closed once Close is called.
+func (s *GroupSubscription) Messages() <-chan *GroupMessage {
+	return s.ch
+}
+
+// Ack marks messageID as done for this consumer's group, so no other consumer
+// in the group ever reclaims it. The message reaper (see startMessageReaper)
+// goes by the group's delivery cursor and the message's age, not by acks.
+// Acking a message this consumer doesn't
+// currently hold the claim for is a no-op (RowsAffected 0, no error).
+func (s *GroupSubscription) Ack(messageID int64) error {
+	return s.db.Model(&MessageGroupClaim{}).
+		Where("channel = ? AND consumer_group = ? AND message_id = ? AND consumer = ?",
+			s.channel, s.group, messageID, s.consumer).
+		Update("acked", true).Error
+}
+
+// Close stops delivering new messages. Already-claimed, unacked messages are
+// left as-is and become reclaimable by another consumer once
+// groupVisibilityTimeout elapses.
+func (s *GroupSubscription) Close() error {
+	s.cancel()
+	return nil
+}
+
+func (s *GroupSubscription) loop(ctx context.Context) {
+	defer close(s.ch)
+
+	wake, cancelWake := broker.subscribe(s.channel)
+	defer cancelWake()
+
+	for {
+		jitter := time.Duration(rand.Int63n(int64(groupPollJitter)))
+		timer := time.NewTimer(groupPollInterval + jitter)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		ids, err := s.claimNew()
+		if err != nil {
+			logs.Errorf("oceanbase consumer group %s/%s: claim new failed: %v", s.channel, s.group, err)
+		}
+
+		reclaimed, err := s.reclaimExpired()
+		if err != nil {
+			logs.Errorf("oceanbase consumer group %s/%s: reclaim failed: %v", s.channel, s.group, err)
+		}
+		ids = append(ids, reclaimed...)
+
+		if len(ids) == 0 {
+			continue
+		}
+		if s.deliver(ctx, ids) {
+			return
+		}
+	}
+}
+
+// claimNew looks past the group's last-delivered cursor for new messages on
+// s.channel and, for each, tries to be the first to insert its claim row -
+// the uniqueIndex on (channel, consumer_group, message_id) means at most one
+// consumer across the whole group wins each message, the same guarantee
+// SELECT ... FOR UPDATE SKIP LOCKED gives, but without needing an existing
+// row to lock.
+func (s *GroupSubscription) claimNew() ([]int64, error) {
+	var cursor MessageGroupCursor
+	if err := s.db.Where("channel = ? AND consumer_group = ?", s.channel, s.group).
+		Attrs(MessageGroupCursor{Channel: s.channel, ConsumerGroup: s.group, LastDeliveredID: -1}).
+		FirstOrCreate(&cursor).Error; err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	if err := s.db.Where("channel = ? AND id > ?", s.channel, cursor.LastDeliveredID).
+		Order("id ASC").Limit(groupBatchSize).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	var claimed []int64
+	maxID := cursor.LastDeliveredID
+	for _, msg := range messages {
+		if msg.ID > maxID {
+			maxID = msg.ID
+		}
+		result := s.db.Exec(
+			`INSERT IGNORE INTO cache_message_group_claims
+				(channel, consumer_group, message_id, consumer, claimed_at, acked, created_at, updated_at)
+			VALUES (?, ?, ?, ?, NOW(), false, NOW(), NOW())`,
+			s.channel, s.group, msg.ID, s.consumer)
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			claimed = append(claimed, msg.ID)
+		}
+	}
+
+	if err := s.db.Model(&MessageGroupCursor{}).
+		Where("channel = ? AND consumer_group = ?", s.channel, s.group).
+		Update("last_delivered_id", maxID).Error; err != nil {
+		return claimed, err
+	}
+
+	return claimed, nil
+}
+
+// reclaimExpired takes over claims in this group that have sat unacked past
+// groupVisibilityTimeout, the way Redis Streams' XCLAIM redelivers a pending
+// entry nobody acked in time. FOR UPDATE SKIP LOCKED here only ever
+// contends against other consumers in the same group (it's scoped by
+// consumer_group), so it can't delay a different group's delivery of the
+// same message.
+func (s *GroupSubscription) reclaimExpired() ([]int64, error) {
+	var ids []int64
+	cutoff := time.Now().Add(-groupVisibilityTimeout)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var claims []MessageGroupClaim
+		if err := tx.Raw(
+			`SELECT id, message_id FROM cache_message_group_claims
+				WHERE channel = ? AND consumer_group = ? AND acked = false AND claimed_at < ?
+				ORDER BY message_id ASC LIMIT ? FOR UPDATE SKIP LOCKED`,
+			s.channel, s.group, cutoff, groupBatchSize).Scan(&claims).Error; err != nil {
+			return err
+		}
+		if len(claims) == 0 {
+			return nil
+		}
+
+		claimIDs := make([]int64, len(claims))
+		for i, c := range claims {
+			claimIDs[i] = c.ID
+			ids = append(ids, c.MessageID)
+		}
+
+		return tx.Model(&MessageGroupClaim{}).Where("id IN ?", claimIDs).
+			Updates(map[string]interface{}{"consumer": s.consumer, "claimed_at": time.Now()}).Error
+	})
+
+	return ids, err
+}
+
+// deliver loads the message bodies for ids and sends each on s.ch, returning
+// true if ctx was cancelled mid-delivery (the caller should stop the loop).
+func (s *GroupSubscription) deliver(ctx context.Context, ids []int64) bool {
+	var messages []Message
+	if err := s.db.Where("id IN ?", ids).Order("id ASC").Find(&messages).Error; err != nil {
+		logs.Errorf("oceanbase consumer group %s/%s: load messages failed: %v", s.channel, s.group, err)
+		return false
+	}
+
+	for _, msg := range messages {
+		select {
+		case s.ch <- &GroupMessage{ID: msg.ID, Channel: msg.Channel, Message: msg.Message}:
+		case <-ctx.Done():
+			return true
+		}
+	}
+	return false
+}
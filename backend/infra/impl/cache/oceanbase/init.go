@@ -17,14 +17,18 @@
 package oceanbase
 
 import (
+	"os"
+
 	"gorm.io/gorm"
 
 	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+	"github.com/coze-dev/coze-studio/backend/infra/impl/cache/oceanbase/respserver"
 	"github.com/coze-dev/coze-studio/backend/pkg/logs"
 )
 
-// InitOceanBaseCache 初始化OceanBase缓存
-func InitOceanBaseCache(db *gorm.DB) error {
+// InitOceanBaseCache 初始化OceanBase缓存。opts透传给InitOceanBaseClient，用于
+// 选择Set编码非[]byte/string值时使用的codec/压缩方式，见codec.go。
+func InitOceanBaseCache(db *gorm.DB, opts ...ClientOption) error {
 	// 设置默认的Nil错误
 	cache.SetDefaultNilError(cache.ErrNotFound)
 
@@ -34,12 +38,31 @@ func InitOceanBaseCache(db *gorm.DB) error {
 	}
 
 	// 初始化缓存客户端
-	InitOceanBaseClient(db)
+	InitOceanBaseClient(db, opts...)
+
+	// OCEANBASE_CACHE_RESP_ADDR可选地在该地址上暴露一个RESP2端点（如
+	// ":6380"），让redis-cli/go-redis等只会说Redis协议的工具能直接连上这个
+	// OceanBase缓存，不配置则不启动，不影响现有的cache.Client/Cmdable调用方。
+	if addr := os.Getenv("OCEANBASE_CACHE_RESP_ADDR"); addr != "" {
+		startRESPServer(addr)
+	}
 
 	logs.Info("OceanBase cache initialized successfully")
 	return nil
 }
 
+// startRESPServer runs the RESP2 listener in the background; a failure to
+// bind is logged rather than failing cache init, since the RESP endpoint is
+// an optional convenience, not something the rest of the app depends on.
+func startRESPServer(addr string) {
+	srv := respserver.New(GetCacheClient())
+	go func() {
+		if err := srv.ListenAndServe(addr); err != nil {
+			logs.Errorf("oceanbase respserver stopped: %v", err)
+		}
+	}()
+}
+
 // checkAndCreateTables 检查并创建缓存表
 func checkAndCreateTables(db *gorm.DB) error {
 	// 自动迁移表结构
@@ -59,6 +82,26 @@ func checkAndCreateTables(db *gorm.DB) error {
 		return err
 	}
 
+	if err := db.AutoMigrate(&MessageGroupCursor{}); err != nil {
+		return err
+	}
+
+	if err := db.AutoMigrate(&MessageGroupClaim{}); err != nil {
+		return err
+	}
+
+	if err := db.AutoMigrate(&CacheList{}); err != nil {
+		return err
+	}
+
+	if err := db.AutoMigrate(&CacheSet{}); err != nil {
+		return err
+	}
+
+	if err := db.AutoMigrate(&CacheZSet{}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -72,3 +115,15 @@ func GetCacheCmdable() cache.Cmdable {
 	client := cache.GetClient()
 	return NewCmdableAdapter(client)
 }
+
+// init registers this backend under the name "oceanbase" so appinfra.Init
+// can select it through cache.NewCmdable without importing this package
+// directly.
+func init() {
+	cache.RegisterFactory("oceanbase", func(db *gorm.DB) (cache.Cmdable, error) {
+		if err := InitOceanBaseCache(db); err != nil {
+			return nil, err
+		}
+		return GetCacheCmdable(), nil
+	})
+}
@@ -0,0 +1,272 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+)
+
+const (
+	// listGapSize is the spacing left between adjacent elements' positions so
+	// a push can slot a new row in at either end without touching the rest.
+	listGapSize = 1 << 16
+
+	// listOverflowGuard bounds how far position can drift from zero before a
+	// push renumbers the whole list, so a key that's pushed to for a very
+	// long time never runs off the end of int64.
+	listOverflowGuard = int64(1) << 60
+)
+
+// LPush 实现ListCmdable接口：复用 cache_lists 表，用单调递减的 position
+// 把新元素放到表头，避免每次 push 都重写整张表。
+func (c *CmdableAdapter) LPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	db := clientDB(c.client)
+
+	var count int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		minPos, err := listBoundary(tx, key, true)
+		if err != nil {
+			return err
+		}
+		if minPos-int64(len(values))*listGapSize < -listOverflowGuard {
+			if err := renumberList(tx, key); err != nil {
+				return err
+			}
+			if minPos, err = listBoundary(tx, key, true); err != nil {
+				return err
+			}
+		}
+
+		for i, v := range values {
+			row := CacheList{
+				CacheKey:   key,
+				Position:   minPos - int64(i+1)*listGapSize,
+				Value:      toBytes(v),
+				ExpireTime: time.Now().Add(noExpirySentinel),
+			}
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&CacheList{}).Where("cache_key = ?", key).Count(&count).Error
+	})
+	if err != nil {
+		return &IntCmdAdapter{err: err}
+	}
+	return &IntCmdAdapter{val: count}
+}
+
+// RPush 实现ListCmdable接口：与 LPush 对称，用单调递增的 position 把新元素放到表尾。
+func (c *CmdableAdapter) RPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	db := clientDB(c.client)
+
+	var count int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		maxPos, err := listBoundary(tx, key, false)
+		if err != nil {
+			return err
+		}
+		if maxPos+int64(len(values))*listGapSize > listOverflowGuard {
+			if err := renumberList(tx, key); err != nil {
+				return err
+			}
+			if maxPos, err = listBoundary(tx, key, false); err != nil {
+				return err
+			}
+		}
+
+		for i, v := range values {
+			row := CacheList{
+				CacheKey:   key,
+				Position:   maxPos + int64(i+1)*listGapSize,
+				Value:      toBytes(v),
+				ExpireTime: time.Now().Add(noExpirySentinel),
+			}
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&CacheList{}).Where("cache_key = ?", key).Count(&count).Error
+	})
+	if err != nil {
+		return &IntCmdAdapter{err: err}
+	}
+	return &IntCmdAdapter{val: count}
+}
+
+// LIndex 实现ListCmdable接口
+func (c *CmdableAdapter) LIndex(ctx context.Context, key string, index int64) cache.StringCmd {
+	db := clientDB(c.client)
+
+	count, err := listLen(db, key)
+	if err != nil {
+		return &StringCmdAdapter{err: err}
+	}
+	idx, ok := resolveListIndex(count, index)
+	if !ok {
+		return &StringCmdAdapter{err: cache.ErrNotFound}
+	}
+
+	var row CacheList
+	err = db.Where("cache_key = ? AND expire_time > ?", key, time.Now()).
+		Order("position ASC").Offset(int(idx)).Limit(1).First(&row).Error
+	if err != nil {
+		return &StringCmdAdapter{err: cache.ErrNotFound}
+	}
+	return &StringCmdAdapter{val: string(row.Value)}
+}
+
+// LSet 实现ListCmdable接口
+func (c *CmdableAdapter) LSet(ctx context.Context, key string, index int64, value interface{}) cache.StatusCmd {
+	db := clientDB(c.client)
+
+	count, err := listLen(db, key)
+	if err != nil {
+		return &StatusCmdAdapter{err: err}
+	}
+	idx, ok := resolveListIndex(count, index)
+	if !ok {
+		return &StatusCmdAdapter{err: cache.ErrNotFound}
+	}
+
+	var row CacheList
+	if err := db.Where("cache_key = ? AND expire_time > ?", key, time.Now()).
+		Order("position ASC").Offset(int(idx)).Limit(1).First(&row).Error; err != nil {
+		return &StatusCmdAdapter{err: cache.ErrNotFound}
+	}
+	if err := db.Model(&CacheList{}).Where("id = ?", row.ID).Update("value", toBytes(value)).Error; err != nil {
+		return &StatusCmdAdapter{err: err}
+	}
+	return &StatusCmdAdapter{}
+}
+
+// LPop 实现ListCmdable接口
+func (c *CmdableAdapter) LPop(ctx context.Context, key string) cache.StringCmd {
+	db := clientDB(c.client)
+
+	var val []byte
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var row CacheList
+		if err := tx.Where("cache_key = ? AND expire_time > ?", key, time.Now()).
+			Order("position ASC").Limit(1).First(&row).Error; err != nil {
+			if err.Error() == "record not found" || err.Error() == "gorm.ErrRecordNotFound" {
+				return cache.ErrNotFound
+			}
+			return err
+		}
+		val = row.Value
+		return tx.Delete(&row).Error
+	})
+	if err != nil {
+		return &StringCmdAdapter{err: err}
+	}
+	return &StringCmdAdapter{val: string(val)}
+}
+
+// LRange 实现ListCmdable接口
+func (c *CmdableAdapter) LRange(ctx context.Context, key string, start, stop int64) cache.StringSliceCmd {
+	db := clientDB(c.client)
+
+	count, err := listLen(db, key)
+	if err != nil {
+		return &StringSliceCmdAdapter{err: err}
+	}
+	if count == 0 {
+		return &StringSliceCmdAdapter{val: []string{}}
+	}
+
+	if start < 0 {
+		start = count + start
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop < 0 {
+		stop = count + stop
+	}
+	if stop >= count {
+		stop = count - 1
+	}
+	if start > stop || start >= count {
+		return &StringSliceCmdAdapter{val: []string{}}
+	}
+
+	var rows []CacheList
+	err = db.Where("cache_key = ? AND expire_time > ?", key, time.Now()).
+		Order("position ASC").Offset(int(start)).Limit(int(stop - start + 1)).Find(&rows).Error
+	if err != nil {
+		return &StringSliceCmdAdapter{err: err}
+	}
+
+	vals := make([]string, len(rows))
+	for i, row := range rows {
+		vals[i] = string(row.Value)
+	}
+	return &StringSliceCmdAdapter{val: vals}
+}
+
+// listLen 返回某个 key 当前未过期的元素个数
+func listLen(db *gorm.DB, key string) (int64, error) {
+	var count int64
+	err := db.Model(&CacheList{}).Where("cache_key = ? AND expire_time > ?", key, time.Now()).Count(&count).Error
+	return count, err
+}
+
+// resolveListIndex 把redis风格的索引（支持负数表示从尾部数）转换成从0开始的偏移量
+func resolveListIndex(count, index int64) (int64, bool) {
+	if index < 0 {
+		index = count + index
+	}
+	if index < 0 || index >= count {
+		return 0, false
+	}
+	return index, true
+}
+
+// listBoundary 返回某个 key 当前的最小（或最大）position，key 不存在时返回 0
+func listBoundary(tx *gorm.DB, key string, min bool) (int64, error) {
+	agg := "MAX"
+	if min {
+		agg = "MIN"
+	}
+	var pos int64
+	err := tx.Model(&CacheList{}).Where("cache_key = ?", key).
+		Select(fmt.Sprintf("COALESCE(%s(position), 0)", agg)).Scan(&pos).Error
+	return pos, err
+}
+
+// renumberList 把某个 key 下所有元素按当前顺序重新编号，position 间距恢复为 listGapSize，
+// 用于 push 导致 position 逼近溢出阈值时重新铺开空间。
+func renumberList(tx *gorm.DB, key string) error {
+	var rows []CacheList
+	if err := tx.Where("cache_key = ?", key).Order("position ASC").Find(&rows).Error; err != nil {
+		return err
+	}
+	for i, row := range rows {
+		pos := int64(i+1) * listGapSize
+		if err := tx.Model(&CacheList{}).Where("id = ?", row.ID).Update("position", pos).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import "time"
+
+// Lock 获取分布式锁：复用 cache_kvs 表，owner 写入 cache_value，过期时间写入
+// expire_time。先尝试 INSERT IGNORE 抢占一把全新的锁；如果该行已存在，再用
+// 条件 UPDATE 在 cache_value=owner（owner自己重入）或 expire_time<=NOW()
+// （上一把锁已过期，谁都能抢）时抢占，这两条路径在 READ COMMITTED 下都是原子的。
+func (c Client) Lock(key string, owner string, ttl time.Duration) (bool, error) {
+	expireTime := time.Now().Add(ttl)
+
+	insert := c.DB.Exec(
+		`INSERT IGNORE INTO cache_kvs (cache_key, cache_value, expire_time, created_at, updated_at)
+			VALUES (?, ?, ?, NOW(), NOW())`,
+		key, []byte(owner), expireTime)
+	if insert.Error != nil {
+		return false, insert.Error
+	}
+	if insert.RowsAffected == 1 {
+		return true, nil
+	}
+
+	return c.casLock(key, owner, expireTime, true)
+}
+
+// RenewLock 续期：只有owner当前确实持有这把锁时才会续期，不会像Lock那样在锁
+// 已过期时顺手抢占——调用方用RenewLock就是想确认自己还拿着锁，而不是去抢一把
+// 新锁，这两者的失败/成功语义不应该混在一起。
+func (c Client) RenewLock(key string, owner string, ttl time.Duration) (bool, error) {
+	return c.casLock(key, owner, time.Now().Add(ttl), false)
+}
+
+// casLock is the shared conditional UPDATE behind Lock and RenewLock.
+// allowExpired also matches a row whose expire_time has already passed
+// (Lock's "steal an abandoned lock" path); RenewLock never sets it, since
+// renewing should only succeed for the lock's current, still-valid owner.
+func (c Client) casLock(key string, owner string, expireTime time.Time, allowExpired bool) (bool, error) {
+	where := "cache_key = ? AND cache_value = ?"
+	args := []interface{}{[]byte(owner), expireTime, key, []byte(owner)}
+	if allowExpired {
+		where = "cache_key = ? AND (cache_value = ? OR expire_time <= NOW())"
+	}
+
+	result := c.DB.Exec(
+		"UPDATE cache_kvs SET cache_value = ?, expire_time = ?, updated_at = NOW() WHERE "+where,
+		args...)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Unlock 释放锁：只有owner当前持有时才会真正删除这一行，释放一把自己并不
+// 持有的锁是空操作而非错误，和真实分布式锁实现的语义一致。
+func (c Client) Unlock(key string, owner string) (bool, error) {
+	result := c.DB.Exec("DELETE FROM cache_kvs WHERE cache_key = ? AND cache_value = ?", key, []byte(owner))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -20,9 +20,15 @@ import "time"
 
 // CacheKV 缓存键值对表
 type CacheKV struct {
-	ID         int64     `json:"id" gorm:"column:id;primaryKey;type:bigint(20) auto_increment"`
-	CacheKey   string    `json:"cache_key" gorm:"column:cache_key;type:varchar(256);not null;unique"`
-	CacheValue []byte    `json:"cache_value" gorm:"column:cache_value;type:longblob;not null"`
+	ID         int64  `json:"id" gorm:"column:id;primaryKey;type:bigint(20) auto_increment"`
+	CacheKey   string `json:"cache_key" gorm:"column:cache_key;type:varchar(256);not null;unique"`
+	CacheValue []byte `json:"cache_value" gorm:"column:cache_value;type:longblob;not null"`
+	// Encoding标记CacheValue的写入形式：encodingRaw（默认，[]byte/string原样写入，
+	// 完全兼容这个字段加入之前写的所有行）或encodingEnveloped（见codec.go，值先经过
+	// 可插拔的cache.Codec编码，再裹上TLV头和可选压缩）。自描述成这一列而不是让
+	// GetBytes去嗅探cache_value的内容，是为了不会把恰好以TLV magic字节开头的原始
+	// 数据误判成编码过的值。
+	Encoding   byte      `json:"encoding" gorm:"column:encoding;not null;default:0"`
 	ExpireTime time.Time `json:"expire_time" gorm:"index"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
@@ -75,3 +81,89 @@ type MessageSubscribe struct {
 func (MessageSubscribe) TableName() string {
 	return "cache_message_subscribes"
 }
+
+// MessageGroupCursor 记录一个消费组在某个channel上已经"投递过"的最新消息ID
+// （哪怕还没被任何consumer ack），类似Redis Streams里消费组的last-delivered-id，
+// 用来决定下一批轮询该从哪条消息之后开始找新消息。
+type MessageGroupCursor struct {
+	ID              int64     `json:"id" gorm:"column:id;primaryKey;type:bigint(20) auto_increment"`
+	Channel         string    `json:"channel" gorm:"column:channel;type:varchar(1024);not null;uniqueIndex:idx_group_cursor"`
+	ConsumerGroup   string    `json:"consumer_group" gorm:"column:consumer_group;type:varchar(256);not null;uniqueIndex:idx_group_cursor"`
+	LastDeliveredID int64     `json:"last_delivered_id" gorm:"column:last_delivered_id;type:bigint(20);not null;default:-1"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (MessageGroupCursor) TableName() string {
+	return "cache_message_group_cursors"
+}
+
+// MessageGroupClaim 是一条消息在某个消费组里的投递状态，类似Redis Streams的
+// pending entries list：一条消息第一次被某个消费组看到时插入一行，
+// consumer/claimed_at记录当前由谁持有、何时认领，acked为true之前它都算未完成，
+// claimed_at早于可见性超时的行可以被组内其它consumer重新认领。
+type MessageGroupClaim struct {
+	ID            int64     `json:"id" gorm:"column:id;primaryKey;type:bigint(20) auto_increment"`
+	Channel       string    `json:"channel" gorm:"column:channel;type:varchar(1024);not null;uniqueIndex:idx_group_claim"`
+	ConsumerGroup string    `json:"consumer_group" gorm:"column:consumer_group;type:varchar(256);not null;uniqueIndex:idx_group_claim"`
+	MessageID     int64     `json:"message_id" gorm:"column:message_id;type:bigint(20);not null;uniqueIndex:idx_group_claim"`
+	Consumer      string    `json:"consumer" gorm:"column:consumer;type:varchar(256);not null"`
+	ClaimedAt     time.Time `json:"claimed_at" gorm:"column:claimed_at;index:idx_group_claim_reclaim"`
+	Acked         bool      `json:"acked" gorm:"column:acked;not null;default:false;index:idx_group_claim_reclaim"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (MessageGroupClaim) TableName() string {
+	return "cache_message_group_claims"
+}
+
+// CacheList 列表表，通过单调递增/递减的 position 维护顺序，
+// 避免每次 LPush/RPush 都要重写整张表
+type CacheList struct {
+	ID         int64     `json:"id" gorm:"column:id;primaryKey;type:bigint(20) auto_increment"`
+	CacheKey   string    `json:"cache_key" gorm:"column:cache_key;type:varchar(256);not null;index:idx_list_key_pos"`
+	Position   int64     `json:"position" gorm:"column:position;not null;index:idx_list_key_pos"`
+	Value      []byte    `json:"value" gorm:"column:value;type:longblob;not null"`
+	ExpireTime time.Time `json:"expire_time" gorm:"index"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CacheList) TableName() string {
+	return "cache_lists"
+}
+
+// CacheSet 集合表
+type CacheSet struct {
+	ID         int64     `json:"id" gorm:"column:id;primaryKey;type:bigint(20) auto_increment"`
+	CacheKey   string    `json:"cache_key" gorm:"column:cache_key;type:varchar(256);not null;uniqueIndex:idx_set_key_member"`
+	Member     string    `json:"member" gorm:"column:member;type:varchar(512);not null;uniqueIndex:idx_set_key_member"`
+	ExpireTime time.Time `json:"expire_time" gorm:"index"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CacheSet) TableName() string {
+	return "cache_sets"
+}
+
+// CacheZSet 有序集合表
+type CacheZSet struct {
+	ID         int64     `json:"id" gorm:"column:id;primaryKey;type:bigint(20) auto_increment"`
+	CacheKey   string    `json:"cache_key" gorm:"column:cache_key;type:varchar(256);not null;uniqueIndex:idx_zset_key_member"`
+	Member     string    `json:"member" gorm:"column:member;type:varchar(512);not null;uniqueIndex:idx_zset_key_member"`
+	Score      float64   `json:"score" gorm:"column:score;not null;index:idx_zset_key_score"`
+	ExpireTime time.Time `json:"expire_time" gorm:"index"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CacheZSet) TableName() string {
+	return "cache_zsets"
+}
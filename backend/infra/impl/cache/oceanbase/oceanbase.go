@@ -17,6 +17,7 @@
 package oceanbase
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -24,49 +25,51 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
-	"github.com/coze-dev/coze-studio/backend/pkg/logs"
 )
 
 // Context 缓存上下文
 type Context struct {
 	*gorm.DB
+	codec codecConfig
 }
 
 // Client OceanBase缓存客户端
 type Client struct {
 	*gorm.DB
+	codec codecConfig
 }
 
 // Get 获取缓存客户端
 func (c *Context) Get() cache.Client {
-	return &Client{c.DB}
+	return &Client{DB: c.DB, codec: c.codec}
 }
 
-// InitOceanBaseClient 初始化OceanBase缓存客户端
-func InitOceanBaseClient(db *gorm.DB) {
-	cache.SetClient(&Client{db})
-	go cleanExpiredCache(db)
+// InitOceanBaseClient 初始化OceanBase缓存客户端。opts可以用WithCodec/WithCompression
+// 挑选Set在遇到无法直接存成[]byte/string的值时使用的编码方式，不传则使用
+// defaultCodecConfig（JSON，不压缩）。
+func InitOceanBaseClient(db *gorm.DB, opts ...ClientOption) {
+	cfg := defaultCodecConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cache.SetClient(&Client{DB: db, codec: cfg})
+	startExpirySweeper(db)
+	startMessageReaper(db)
 }
 
-// cleanExpiredCache 清理过期缓存
-func cleanExpiredCache(db *gorm.DB) {
-	time.Sleep(time.Minute * 5)
-
-	for {
-		logs.Info("cleaning outdated cache and messages")
-		now := time.Now()
-
-		result := db.Where("expire_time <= ?", now).Delete(&CacheKV{})
-		if result.Error != nil {
-			logs.Errorf("failed to clean expired kv cache: %v", result.Error)
-		} else {
-			logs.Infof("cleaned %d expired kv cache", result.RowsAffected)
-		}
-		time.Sleep(time.Minute * 1)
+// expireTimeFor 计算写入 expire_time 列的值。expire <= 0（包括调用方传入0表示
+// "不设置过期时间"，对齐 go-redis 中 expiration == 0 即永不过期的语义）时使用
+// noExpirySentinel，让该行在所有 "expire_time > NOW()" 的读路径里都视为未过期。
+func expireTimeFor(expire time.Duration) time.Time {
+	if expire <= 0 {
+		return time.Now().Add(noExpirySentinel)
 	}
+	return time.Now().Add(expire)
 }
 
-// toBytes 将数据转换为字节数组
+// toBytes 将数据转换为字节数组。列表/集合等结构用它存成员值；它们的元素从来
+// 不是"请把任意结构体存进缓存"的入口（那是cache_kvs.Set的职责），所以不经过
+// codecConfig，调用方传[]byte/string以外的类型时继续保持原有的静默nil语义。
 func toBytes(data any) []byte {
 	if bytes, ok := data.([]byte); ok {
 		return bytes
@@ -77,9 +80,11 @@ func toBytes(data any) []byte {
 	}
 }
 
-// convertRegexToSQL 将正则表达式转换为SQL模式
+// convertRegexToSQL 将glob模式（*、?通配符）转换为SQL LIKE模式
 func convertRegexToSQL(pattern string) string {
-	return strings.ReplaceAll(pattern, "*", "%")
+	pattern = strings.ReplaceAll(pattern, "*", "%")
+	pattern = strings.ReplaceAll(pattern, "?", "_")
+	return pattern
 }
 
 // Close 关闭缓存客户端
@@ -87,34 +92,79 @@ func (c Client) Close() error {
 	return nil
 }
 
-// Set 设置缓存
+// Set 设置缓存。value为[]byte/string时原样写入，其它类型（例如结构体）交给
+// c.codec编码并裹上TLV头再写入，见codec.go的encodeValue - 这条路径在这个字段
+// 加入之前总是悄悄把cache_value写成nil。
 func (c Client) Set(key string, value any, expire time.Duration) error {
-	val := toBytes(value)
-	expireTime := time.Now().Add(expire)
+	val, encoding, err := encodeValue(c.codec, value)
+	if err != nil {
+		return err
+	}
+	expireTime := expireTimeFor(expire)
 
 	// 使用 INSERT ... ON DUPLICATE KEY UPDATE 来避免并发写入问题
-	sql := `INSERT INTO cache_kvs (cache_key, cache_value, expire_time, created_at, updated_at) 
-			VALUES (?, ?, ?, NOW(), NOW()) 
-			ON DUPLICATE KEY UPDATE 
-			cache_value = VALUES(cache_value), 
-			expire_time = VALUES(expire_time), 
+	sql := `INSERT INTO cache_kvs (cache_key, cache_value, encoding, expire_time, created_at, updated_at)
+			VALUES (?, ?, ?, ?, NOW(), NOW())
+			ON DUPLICATE KEY UPDATE
+			cache_value = VALUES(cache_value),
+			encoding = VALUES(encoding),
+			expire_time = VALUES(expire_time),
 			updated_at = NOW()`
 
-	return c.DB.Exec(sql, key, val, expireTime).Error
+	return c.DB.Exec(sql, key, val, encoding, expireTime).Error
 }
 
-// GetBytes 获取字节数组缓存
+// GetBytes 获取字节数组缓存。对于c.Set用codec编码过的值，透明地剥掉TLV头并
+// 按需解压缩，返回的是codec编码后的payload（例如JSON字节），而不是裹着头的
+// 原始存储内容；想要解码成具体类型用GetObject。
 func (c Client) GetBytes(key string) ([]byte, error) {
+	cacheKV, err := c.getCacheKV(key)
+	if err != nil {
+		return nil, err
+	}
+
+	_, payload, err := decodeStoredValue(cacheKV.CacheValue, cacheKV.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// GetObject 获取缓存并用存储时记录的codec解码进out，例如 GetObject(key, &v)。
+// 对从未被编码过（即用[]byte/string原样存入）的键返回错误，因为没有codec可用。
+func (c Client) GetObject(key string, out any) error {
+	cacheKV, err := c.getCacheKV(key)
+	if err != nil {
+		return err
+	}
+
+	codecID, payload, err := decodeStoredValue(cacheKV.CacheValue, cacheKV.Encoding)
+	if err != nil {
+		return err
+	}
+	if cacheKV.Encoding == encodingRaw {
+		return fmt.Errorf("cache: value at %q was stored raw, not through a codec", key)
+	}
+
+	codec, err := cache.CodecByID(codecID)
+	if err != nil {
+		return err
+	}
+	return codec.Decode(payload, out)
+}
+
+// getCacheKV fetches the still-live row for key, translating GORM's "not
+// found" into cache.ErrNotFound the same way every other lookup here does.
+func (c Client) getCacheKV(key string) (CacheKV, error) {
 	var cacheKV CacheKV
 	result := c.DB.Where("cache_key = ? AND expire_time > ?", key, time.Now()).First(&cacheKV)
 	if result.Error != nil {
 		if result.Error.Error() == "record not found" || result.Error.Error() == "gorm.ErrRecordNotFound" {
-			return nil, cache.ErrNotFound
+			return CacheKV{}, cache.ErrNotFound
 		}
-		return nil, result.Error
+		return CacheKV{}, result.Error
 	}
-
-	return cacheKV.CacheValue, nil
+	return cacheKV, nil
 }
 
 // GetString 获取字符串缓存
@@ -229,14 +279,17 @@ func (c Client) ScanMapStream(key string, cursor uint64, match string, count int
 
 // SetNX 设置键值对（仅当键不存在时）
 func (c Client) SetNX(key string, value any, expire time.Duration) (bool, error) {
-	val := toBytes(value)
-	expireTime := time.Now().Add(expire)
+	val, encoding, err := encodeValue(c.codec, value)
+	if err != nil {
+		return false, err
+	}
+	expireTime := expireTimeFor(expire)
 
 	// 使用 INSERT IGNORE 来实现 SetNX，避免并发写入问题
-	sql := `INSERT IGNORE INTO cache_kvs (cache_key, cache_value, expire_time, created_at, updated_at) 
-			VALUES (?, ?, ?, NOW(), NOW())`
+	sql := `INSERT IGNORE INTO cache_kvs (cache_key, cache_value, encoding, expire_time, created_at, updated_at)
+			VALUES (?, ?, ?, ?, NOW(), NOW())`
 
-	result := c.DB.Exec(sql, key, val, expireTime)
+	result := c.DB.Exec(sql, key, val, encoding, expireTime)
 	if result.Error != nil {
 		return false, result.Error
 	}
@@ -256,10 +309,49 @@ func (c Client) Expire(key string, expire time.Duration) (bool, error) {
 	return result.RowsAffected > 0, result.Error
 }
 
+// MGet 批量获取字节数组缓存，一次往返而不是逐个 GetBytes
+func (c Client) MGet(keys []string) (map[string][]byte, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	pipe := c.Pipeline()
+	results := make([]*cache.PipelineResult, len(keys))
+	for i, key := range keys {
+		results[i] = pipe.Get(key)
+	}
+
+	if err := pipe.Exec(context.Background()); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]byte, len(keys))
+	for i, key := range keys {
+		if results[i].Err == nil {
+			out[key] = results[i].Bytes
+		}
+	}
+	return out, nil
+}
+
+// MSet 批量设置缓存，一次往返而不是逐个 Set
+func (c Client) MSet(kv map[string]cache.CacheEntry) error {
+	if len(kv) == 0 {
+		return nil
+	}
+
+	pipe := c.Pipeline()
+	for key, entry := range kv {
+		pipe.Set(key, entry.Value, entry.Expire)
+	}
+
+	return pipe.Exec(context.Background())
+}
+
 // Transaction 事务处理
 func (c Client) Transaction(fn func(context cache.Context) error) error {
 	return c.DB.Transaction(func(tx *gorm.DB) error {
-		context := &Context{tx}
+		context := &Context{DB: tx, codec: c.codec}
 		return fn(context)
 	})
 }
@@ -276,58 +368,106 @@ func (c Client) Publish(channel string, message string) error {
 		return result.Error
 	}
 
+	// 唤醒同进程内的订阅者，不用等到它们下一次轮询tick，见broker.go
+	broker.notify(channel)
+
 	return nil
 }
 
 // Subscribe 订阅消息
 func (c Client) Subscribe(channel string) (<-chan string, func()) {
+	// an ephemeral subscriber is never reused, so it always starts tailing
+	// from the current end of the channel.
+	subscriber := fmt.Sprintf("sub_%d", time.Now().UnixNano())
+	return c.subscribe(channel, subscriber, true)
+}
+
+// SubscribeDurable 可续订的订阅：使用稳定的 subscriberID，断线重连后从
+// cache_message_subscribes 中记录的 last_message_id 继续消费，而不是从头或从尾开始。
+func (c Client) SubscribeDurable(channel string, subscriberID string) (<-chan string, func()) {
+	return c.subscribe(channel, subscriberID, false)
+}
+
+// subscribe is the shared implementation behind Subscribe and
+// SubscribeDurable. When ephemeral is true the subscription row is removed
+// on Close so it never leaks; when false it is kept so a later call with the
+// same subscriberID resumes from where it left off.
+func (c Client) subscribe(channel string, subscriber string, ephemeral bool) (<-chan string, func()) {
 	ch := make(chan string, 100)
 	stop := make(chan bool)
 
-	subscriber := fmt.Sprintf("sub_%d", time.Now().UnixNano())
 	var subscription MessageSubscribe
-	c.DB.Model(&MessageSubscribe{}).
-		Where("channel = ? AND subscriber = ?", channel, subscriber).
-		Assign(MessageSubscribe{
+	c.DB.Where("channel = ? AND subscriber = ?", channel, subscriber).
+		Attrs(MessageSubscribe{
 			Channel:       channel,
 			Subscriber:    subscriber,
 			LastMessageId: -1,
 		}).
 		FirstOrCreate(&subscription)
+	if ephemeral {
+		// start at the channel's current end: an ephemeral subscriber only
+		// receives messages published after it subscribed.
+		c.DB.Model(&Message{}).Where("channel = ?", channel).
+			Select("COALESCE(MAX(id), -1)").Scan(&subscription.LastMessageId)
+	}
+
+	// wake fires the instant a same-process Publish lands on channel, so
+	// this loop doesn't have to wait out the rest of its ticker interval;
+	// the ticker stays as the cross-process fallback, see broker.go.
+	wake, cancelWake := broker.subscribe(channel)
 
 	go func() {
 		defer close(ch)
+		defer cancelWake()
 		defer func() {
-			c.DB.Where("channel = ? AND subscriber = ?", channel, subscriber).Delete(&MessageSubscribe{})
+			if ephemeral {
+				c.DB.Where("channel = ? AND subscriber = ?", channel, subscriber).Delete(&MessageSubscribe{})
+			}
 		}()
 		ticker := time.NewTicker(100 * time.Millisecond)
 		defer ticker.Stop()
 
+		poll := func() (done bool) {
+			var messages []Message
+			result := c.DB.Where("channel = ? AND id > ?", channel, subscription.LastMessageId).
+				Order("id ASC").
+				Limit(10).
+				Find(&messages)
+
+			if result.Error != nil {
+				return false
+			}
+
+			for _, msg := range messages {
+				select {
+				case ch <- msg.Message:
+					subscription.LastMessageId = msg.ID
+					c.DB.Model(&MessageSubscribe{}).
+						Where("channel = ? AND subscriber = ?", channel, subscriber).
+						Update("last_message_id", msg.ID)
+				case <-stop:
+					return true
+				}
+			}
+			return false
+		}
+
 		for {
 			select {
 			case <-stop:
 				return
-			case <-ticker.C:
-				var messages []Message
-				result := c.DB.Where("channel = ? AND id > ?", channel, subscription.LastMessageId).
-					Order("id ASC").
-					Limit(10).
-					Find(&messages)
-
-				if result.Error != nil {
-					continue
+			case _, ok := <-wake:
+				if !ok {
+					// broker.go only closes this on cancelWake, which only
+					// runs once this goroutine is already exiting.
+					return
 				}
-
-				for _, msg := range messages {
-					select {
-					case ch <- msg.Message:
-						subscription.LastMessageId = msg.ID
-						c.DB.Model(&MessageSubscribe{}).
-							Where("channel = ? AND subscriber = ?", channel, subscriber).
-							Update("last_message_id", msg.ID)
-					case <-stop:
-						return
-					}
+				if poll() {
+					return
+				}
+			case <-ticker.C:
+				if poll() {
+					return
 				}
 			}
 		}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+)
+
+// TestClient_Get_LazyExpiry sets a 100ms TTL, sleeps past it, and asserts
+// both Get and Count report the key gone even though nothing ever called
+// Del - the expire_time > NOW() predicate on the read path is what's
+// actually supposed to make that true.
+func TestClient_Get_LazyExpiry(t *testing.T) {
+	db := testOceanBaseDB(t)
+	client := Client{DB: db, codec: defaultCodecConfig()}
+
+	key := fmt.Sprintf("test:ttl:lazy-expiry:%p", t)
+	t.Cleanup(func() { db.Exec("DELETE FROM cache_kvs WHERE cache_key = ?", key) })
+
+	if err := client.Set(key, "value", 100*time.Millisecond); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if _, err := client.GetString(key); err != cache.ErrNotFound {
+		t.Fatalf("GetString on an expired key: got err=%v, want cache.ErrNotFound", err)
+	}
+
+	if n, err := client.Count(key); err != nil {
+		t.Fatalf("Count failed: %v", err)
+	} else if n != 0 {
+		t.Fatalf("Count on an expired key: got=%d, want=0", n)
+	}
+}
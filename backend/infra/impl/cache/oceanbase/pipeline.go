@@ -0,0 +1,398 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+)
+
+// defaultPipelineChunkSize bounds how many queued operations of the same
+// kind are flushed in a single multi-row statement, so one oversized
+// pipeline doesn't produce a SQL statement past OceanBase's max packet size.
+const defaultPipelineChunkSize = 500
+
+type pipelineOpKind int
+
+const (
+	opSet pipelineOpKind = iota
+	opGet
+	opDelete
+	opSetNX
+	opSetMapField
+	opGetMapField
+	opExpire
+)
+
+type pipelineOp struct {
+	kind   pipelineOpKind
+	key    string
+	field  string
+	value  any
+	expire time.Duration
+	result *cache.PipelineResult
+}
+
+// oceanbasePipeline is the OceanBase implementation of cache.Pipeline: it
+// queues operations in memory and, on Exec, flushes each kind as one (or a
+// few, if chunked) multi-row SQL statement instead of one round trip per op.
+type oceanbasePipeline struct {
+	db        *gorm.DB
+	codec     codecConfig
+	ops       []*pipelineOp
+	chunkSize int
+}
+
+// Pipeline 返回一个批处理累加器，将 Set/Get/Delete/SetMapField/GetMapField/Expire
+// 调用排队，Exec 时合并为尽量少的 SQL 往返。
+func (c Client) Pipeline() cache.Pipeline {
+	return &oceanbasePipeline{db: c.DB, codec: c.codec, chunkSize: defaultPipelineChunkSize}
+}
+
+func (p *oceanbasePipeline) queue(op *pipelineOp) *cache.PipelineResult {
+	op.result = &cache.PipelineResult{}
+	p.ops = append(p.ops, op)
+	return op.result
+}
+
+func (p *oceanbasePipeline) Set(key string, value any, expire time.Duration) *cache.PipelineResult {
+	return p.queue(&pipelineOp{kind: opSet, key: key, value: value, expire: expire})
+}
+
+func (p *oceanbasePipeline) Get(key string) *cache.PipelineResult {
+	return p.queue(&pipelineOp{kind: opGet, key: key})
+}
+
+func (p *oceanbasePipeline) Delete(key string) *cache.PipelineResult {
+	return p.queue(&pipelineOp{kind: opDelete, key: key})
+}
+
+func (p *oceanbasePipeline) SetNX(key string, value any, expire time.Duration) *cache.PipelineResult {
+	return p.queue(&pipelineOp{kind: opSetNX, key: key, value: value, expire: expire})
+}
+
+func (p *oceanbasePipeline) SetMapField(key string, field string, value string) *cache.PipelineResult {
+	return p.queue(&pipelineOp{kind: opSetMapField, key: key, field: field, value: value})
+}
+
+func (p *oceanbasePipeline) GetMapField(key string, field string) *cache.PipelineResult {
+	return p.queue(&pipelineOp{kind: opGetMapField, key: key, field: field})
+}
+
+func (p *oceanbasePipeline) Expire(key string, expire time.Duration) *cache.PipelineResult {
+	return p.queue(&pipelineOp{kind: opExpire, key: key, expire: expire})
+}
+
+// Exec flushes every queued operation inside a single GORM transaction,
+// grouping same-kind operations into chunkSize-sized multi-row statements.
+func (p *oceanbasePipeline) Exec(ctx context.Context) error {
+	if len(p.ops) == 0 {
+		return nil
+	}
+
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, kind := range []pipelineOpKind{opSet, opGet, opDelete, opSetNX, opSetMapField, opGetMapField, opExpire} {
+			var chunk []*pipelineOp
+			for _, op := range p.ops {
+				if op.kind != kind {
+					continue
+				}
+				chunk = append(chunk, op)
+				if len(chunk) == p.chunkSize {
+					if err := flushChunk(tx, p.codec, kind, chunk); err != nil {
+						return err
+					}
+					chunk = nil
+				}
+			}
+			if len(chunk) > 0 {
+				if err := flushChunk(tx, p.codec, kind, chunk); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func flushChunk(tx *gorm.DB, codec codecConfig, kind pipelineOpKind, ops []*pipelineOp) error {
+	switch kind {
+	case opSet:
+		return flushSet(tx, codec, ops)
+	case opGet:
+		return flushGet(tx, ops)
+	case opDelete:
+		return flushDelete(tx, ops)
+	case opSetNX:
+		return flushSetNX(tx, codec, ops)
+	case opSetMapField:
+		return flushSetMapField(tx, ops)
+	case opGetMapField:
+		return flushGetMapField(tx, ops)
+	case opExpire:
+		return flushExpire(tx, ops)
+	}
+	return nil
+}
+
+func flushSet(tx *gorm.DB, codec codecConfig, ops []*pipelineOp) error {
+	valuesSQL := ""
+	args := make([]interface{}, 0, len(ops)*4)
+	for _, op := range ops {
+		val, encoding, err := encodeValue(codec, op.value)
+		if err != nil {
+			op.result.Err = err
+			continue
+		}
+		if valuesSQL != "" {
+			valuesSQL += ", "
+		}
+		valuesSQL += "(?, ?, ?, ?, NOW(), NOW())"
+		args = append(args, op.key, val, encoding, expireTimeFor(op.expire))
+	}
+	if valuesSQL == "" {
+		return nil
+	}
+
+	sql := fmt.Sprintf(`INSERT INTO cache_kvs (cache_key, cache_value, encoding, expire_time, created_at, updated_at)
+		VALUES %s
+		ON DUPLICATE KEY UPDATE
+		cache_value = VALUES(cache_value),
+		encoding = VALUES(encoding),
+		expire_time = VALUES(expire_time),
+		updated_at = NOW()`, valuesSQL)
+
+	err := tx.Exec(sql, args...).Error
+	for _, op := range ops {
+		if op.result.Err == nil {
+			op.result.Err = err
+		}
+	}
+	return err
+}
+
+func flushGet(tx *gorm.DB, ops []*pipelineOp) error {
+	keys := make([]string, len(ops))
+	for i, op := range ops {
+		keys[i] = op.key
+	}
+
+	var rows []CacheKV
+	err := tx.Where("cache_key IN ? AND expire_time > ?", keys, time.Now()).Find(&rows).Error
+	if err != nil {
+		for _, op := range ops {
+			op.result.Err = err
+		}
+		return err
+	}
+
+	byKey := make(map[string]CacheKV, len(rows))
+	for _, row := range rows {
+		byKey[row.CacheKey] = row
+	}
+
+	for _, op := range ops {
+		row, ok := byKey[op.key]
+		if !ok {
+			op.result.Err = cache.ErrNotFound
+			continue
+		}
+		_, payload, err := decodeStoredValue(row.CacheValue, row.Encoding)
+		if err != nil {
+			op.result.Err = err
+			continue
+		}
+		op.result.Bytes = payload
+		op.result.Str = string(payload)
+	}
+	return nil
+}
+
+func flushDelete(tx *gorm.DB, ops []*pipelineOp) error {
+	keys := make([]string, len(ops))
+	for i, op := range ops {
+		keys[i] = op.key
+	}
+
+	err := tx.Where("cache_key IN ?", keys).Delete(&CacheKV{}).Error
+	for _, op := range ops {
+		op.result.Err = err
+	}
+	return err
+}
+
+// flushSetNX batches several SetNX calls into one multi-row INSERT IGNORE,
+// then a single follow-up SELECT to tell which ones actually won. A bulk
+// INSERT IGNORE's RowsAffected only gives the total rows inserted across the
+// batch, not which keys they were, so this compares each key's resulting
+// cache_value back against what this op tried to write: if they match, this
+// op reads as the one that set it. A value already stored under the key (or
+// queued earlier in the same batch) that is byte-identical to this op's value
+// therefore reads as a win here, whereas the non-pipelined SetNX reports a
+// loss for it, since its INSERT IGNORE affects no row.
+func flushSetNX(tx *gorm.DB, codec codecConfig, ops []*pipelineOp) error {
+	valuesSQL := ""
+	args := make([]interface{}, 0, len(ops)*4)
+	encoded := make(map[*pipelineOp][]byte, len(ops))
+	for _, op := range ops {
+		val, encoding, err := encodeValue(codec, op.value)
+		if err != nil {
+			op.result.Err = err
+			continue
+		}
+		encoded[op] = val
+		if valuesSQL != "" {
+			valuesSQL += ", "
+		}
+		valuesSQL += "(?, ?, ?, ?, NOW(), NOW())"
+		args = append(args, op.key, val, encoding, expireTimeFor(op.expire))
+	}
+	if valuesSQL == "" {
+		return nil
+	}
+
+	sql := fmt.Sprintf(`INSERT IGNORE INTO cache_kvs (cache_key, cache_value, encoding, expire_time, created_at, updated_at)
+		VALUES %s`, valuesSQL)
+	if err := tx.Exec(sql, args...).Error; err != nil {
+		for _, op := range ops {
+			op.result.Err = err
+		}
+		return err
+	}
+
+	keys := make([]string, len(ops))
+	for i, op := range ops {
+		keys[i] = op.key
+	}
+
+	var rows []CacheKV
+	if err := tx.Where("cache_key IN ?", keys).Find(&rows).Error; err != nil {
+		for _, op := range ops {
+			op.result.Err = err
+		}
+		return err
+	}
+
+	byKey := make(map[string][]byte, len(rows))
+	for _, row := range rows {
+		byKey[row.CacheKey] = row.CacheValue
+	}
+
+	for _, op := range ops {
+		if op.result.Err != nil {
+			continue
+		}
+		stored, ok := byKey[op.key]
+		op.result.Bool = ok && string(stored) == string(encoded[op])
+	}
+	return nil
+}
+
+func flushSetMapField(tx *gorm.DB, ops []*pipelineOp) error {
+	valuesSQL := ""
+	args := make([]interface{}, 0, len(ops)*3)
+	for i, op := range ops {
+		if i > 0 {
+			valuesSQL += ", "
+		}
+		valuesSQL += "(?, ?, ?, NOW(), NOW())"
+		args = append(args, op.key, op.field, op.value)
+	}
+
+	sql := fmt.Sprintf(`INSERT INTO cache_maps (cache_key, cache_field, cache_value, created_at, updated_at)
+		VALUES %s
+		ON DUPLICATE KEY UPDATE
+		cache_value = VALUES(cache_value),
+		updated_at = NOW()`, valuesSQL)
+
+	err := tx.Exec(sql, args...).Error
+	for _, op := range ops {
+		op.result.Err = err
+	}
+	return err
+}
+
+func flushGetMapField(tx *gorm.DB, ops []*pipelineOp) error {
+	keys := make([]string, 0, len(ops))
+	fields := make([]string, 0, len(ops))
+	seenKeys, seenFields := map[string]bool{}, map[string]bool{}
+	for _, op := range ops {
+		if !seenKeys[op.key] {
+			keys = append(keys, op.key)
+			seenKeys[op.key] = true
+		}
+		if !seenFields[op.field] {
+			fields = append(fields, op.field)
+			seenFields[op.field] = true
+		}
+	}
+
+	var rows []CacheMap
+	err := tx.Where("cache_key IN ? AND cache_field IN ?", keys, fields).Find(&rows).Error
+	if err != nil {
+		for _, op := range ops {
+			op.result.Err = err
+		}
+		return err
+	}
+
+	byKeyField := make(map[string]string, len(rows))
+	for _, row := range rows {
+		byKeyField[row.CacheKey+"\x00"+row.CacheField] = row.CacheValue
+	}
+
+	for _, op := range ops {
+		if val, ok := byKeyField[op.key+"\x00"+op.field]; ok {
+			op.result.Str = val
+			op.result.Bytes = []byte(val)
+		} else {
+			op.result.Err = cache.ErrNotFound
+		}
+	}
+	return nil
+}
+
+// flushExpire batches heterogeneous per-key expiry updates into a single
+// UPDATE ... CASE WHEN ... END, since a plain WHERE key IN (?) can't carry a
+// different expire_time per key.
+func flushExpire(tx *gorm.DB, ops []*pipelineOp) error {
+	caseSQL := "CASE cache_key "
+	args := make([]interface{}, 0, len(ops)*2)
+	keys := make([]string, len(ops))
+	for i, op := range ops {
+		caseSQL += "WHEN ? THEN ? "
+		args = append(args, op.key, time.Now().Add(op.expire))
+		keys[i] = op.key
+	}
+	caseSQL += "END"
+
+	args = append(args, keys)
+
+	sql := fmt.Sprintf("UPDATE cache_kvs SET expire_time = %s WHERE cache_key IN ?", caseSQL)
+
+	result := tx.Exec(sql, args...)
+	for _, op := range ops {
+		op.result.Err = result.Error
+		op.result.Bool = result.Error == nil
+	}
+	return result.Error
+}
@@ -0,0 +1,541 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+)
+
+// PipelinerAdapter 实现cache.Pipeliner接口：把排队的命令按种类分组，Exec 时
+// 对每一种分别合并成一条（或几条）多行 SQL，而不是每个排队的命令都单独往返一次。
+type PipelinerAdapter struct {
+	client cache.Client
+
+	order []cache.Cmder
+
+	setOps    []*pipeSetOp
+	getOps    []*pipeGetOp
+	delOps    []*pipeKeysOp
+	hsetOps   []*pipeHSetOp
+	hgetOps   []*pipeHGetOp
+	existsOps []*pipeKeysOp
+	expireOps []*pipeExpireOp
+	incrOps   []*pipeIncrOp
+}
+
+func newPipelinerAdapter(client cache.Client) *PipelinerAdapter {
+	return &PipelinerAdapter{client: client}
+}
+
+func (p *PipelinerAdapter) Pipeline() cache.Pipeliner {
+	return p
+}
+
+type pipeSetOp struct {
+	key    string
+	value  interface{}
+	expire time.Duration
+	cmd    *StatusCmdAdapter
+}
+
+func (p *PipelinerAdapter) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.StatusCmd {
+	cmd := &StatusCmdAdapter{}
+	p.setOps = append(p.setOps, &pipeSetOp{key: key, value: value, expire: expiration, cmd: cmd})
+	p.order = append(p.order, cmd)
+	return cmd
+}
+
+type pipeGetOp struct {
+	key string
+	cmd *StringCmdAdapter
+}
+
+func (p *PipelinerAdapter) Get(ctx context.Context, key string) cache.StringCmd {
+	cmd := &StringCmdAdapter{}
+	p.getOps = append(p.getOps, &pipeGetOp{key: key, cmd: cmd})
+	p.order = append(p.order, cmd)
+	return cmd
+}
+
+type pipeKeysOp struct {
+	keys []string
+	cmd  *IntCmdAdapter
+}
+
+func (p *PipelinerAdapter) Del(ctx context.Context, keys ...string) cache.IntCmd {
+	cmd := &IntCmdAdapter{}
+	if len(keys) > 0 {
+		p.delOps = append(p.delOps, &pipeKeysOp{keys: keys, cmd: cmd})
+	}
+	p.order = append(p.order, cmd)
+	return cmd
+}
+
+func (p *PipelinerAdapter) Exists(ctx context.Context, keys ...string) cache.IntCmd {
+	cmd := &IntCmdAdapter{}
+	if len(keys) > 0 {
+		p.existsOps = append(p.existsOps, &pipeKeysOp{keys: keys, cmd: cmd})
+	}
+	p.order = append(p.order, cmd)
+	return cmd
+}
+
+type pipeHSetOp struct {
+	key    string
+	fields []string
+	values []string
+	cmd    *IntCmdAdapter
+}
+
+func (p *PipelinerAdapter) HSet(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	cmd := &IntCmdAdapter{}
+
+	var fields, vals []string
+	for i := 0; i+1 < len(values); i += 2 {
+		field, ok1 := values[i].(string)
+		val, ok2 := values[i+1].(string)
+		if ok1 && ok2 {
+			fields = append(fields, field)
+			vals = append(vals, val)
+		}
+	}
+	if len(fields) > 0 {
+		p.hsetOps = append(p.hsetOps, &pipeHSetOp{key: key, fields: fields, values: vals, cmd: cmd})
+	}
+	p.order = append(p.order, cmd)
+	return cmd
+}
+
+type pipeHGetOp struct {
+	key string
+	cmd *MapStringStringCmdAdapter
+}
+
+func (p *PipelinerAdapter) HGetAll(ctx context.Context, key string) cache.MapStringStringCmd {
+	cmd := &MapStringStringCmdAdapter{}
+	p.hgetOps = append(p.hgetOps, &pipeHGetOp{key: key, cmd: cmd})
+	p.order = append(p.order, cmd)
+	return cmd
+}
+
+type pipeExpireOp struct {
+	key    string
+	expire time.Duration
+	cmd    *BoolCmdAdapter
+}
+
+func (p *PipelinerAdapter) Expire(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	cmd := &BoolCmdAdapter{}
+	p.expireOps = append(p.expireOps, &pipeExpireOp{key: key, expire: expiration, cmd: cmd})
+	p.order = append(p.order, cmd)
+	return cmd
+}
+
+type pipeIncrOp struct {
+	key   string
+	delta int64
+	cmd   *IntCmdAdapter
+}
+
+func (p *PipelinerAdapter) IncrBy(ctx context.Context, key string, value int64) cache.IntCmd {
+	cmd := &IntCmdAdapter{}
+	p.incrOps = append(p.incrOps, &pipeIncrOp{key: key, delta: value, cmd: cmd})
+	p.order = append(p.order, cmd)
+	return cmd
+}
+
+func (p *PipelinerAdapter) Incr(ctx context.Context, key string) cache.IntCmd {
+	return p.IncrBy(ctx, key, 1)
+}
+
+// Exec 把所有排队的命令包在同一个事务里执行：每种命令合并成一条多行 SQL，
+// 结果按原始 key 反解回各自的 Cmd。事务失败时，事务内已经成功的分组结果也会
+// 随事务回滚失效，但仍会把错误写回每个已排队的 Cmd，方便调用方直接检查。
+func (p *PipelinerAdapter) Exec(ctx context.Context) ([]cache.Cmder, error) {
+	db := clientDB(p.client)
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(p.setOps) > 0 {
+			if err := flushPipeSet(tx, p.setOps); err != nil {
+				return err
+			}
+		}
+		if len(p.getOps) > 0 {
+			if err := flushPipeGet(tx, p.getOps); err != nil {
+				return err
+			}
+		}
+		if len(p.delOps) > 0 {
+			if err := flushPipeDel(tx, p.delOps); err != nil {
+				return err
+			}
+		}
+		if len(p.hsetOps) > 0 {
+			if err := flushPipeHSet(tx, p.hsetOps); err != nil {
+				return err
+			}
+		}
+		if len(p.hgetOps) > 0 {
+			if err := flushPipeHGetAll(tx, p.hgetOps); err != nil {
+				return err
+			}
+		}
+		if len(p.existsOps) > 0 {
+			if err := flushPipeExists(tx, p.existsOps); err != nil {
+				return err
+			}
+		}
+		if len(p.expireOps) > 0 {
+			if err := flushPipeExpire(tx, p.expireOps); err != nil {
+				return err
+			}
+		}
+		if len(p.incrOps) > 0 {
+			if err := flushPipeIncrBy(tx, p.incrOps); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		// The transaction itself may have failed before a given op's flush
+		// function ran (e.g. opening it failed outright), so make sure every
+		// queued Cmd at least carries the transaction's error.
+		for _, cmd := range p.order {
+			switch c := cmd.(type) {
+			case *StatusCmdAdapter:
+				if c.err == nil {
+					c.err = err
+				}
+			case *StringCmdAdapter:
+				if c.err == nil {
+					c.err = err
+				}
+			case *IntCmdAdapter:
+				if c.err == nil {
+					c.err = err
+				}
+			case *BoolCmdAdapter:
+				if c.err == nil {
+					c.err = err
+				}
+			case *MapStringStringCmdAdapter:
+				if c.err == nil {
+					c.err = err
+				}
+			}
+		}
+	}
+
+	return p.order, err
+}
+
+func flushPipeSet(tx *gorm.DB, ops []*pipeSetOp) error {
+	valuesSQL := ""
+	args := make([]interface{}, 0, len(ops)*3)
+	for i, op := range ops {
+		if i > 0 {
+			valuesSQL += ", "
+		}
+		valuesSQL += "(?, ?, ?, NOW(), NOW())"
+		args = append(args, op.key, toBytes(op.value), expireTimeFor(op.expire))
+	}
+
+	sql := fmt.Sprintf(`INSERT INTO cache_kvs (cache_key, cache_value, expire_time, created_at, updated_at)
+		VALUES %s
+		ON DUPLICATE KEY UPDATE
+		cache_value = VALUES(cache_value),
+		expire_time = VALUES(expire_time),
+		updated_at = NOW()`, valuesSQL)
+
+	err := tx.Exec(sql, args...).Error
+	for _, op := range ops {
+		op.cmd.err = err
+	}
+	return err
+}
+
+func flushPipeGet(tx *gorm.DB, ops []*pipeGetOp) error {
+	keys := make([]string, len(ops))
+	for i, op := range ops {
+		keys[i] = op.key
+	}
+
+	var rows []CacheKV
+	if err := tx.Where("cache_key IN ? AND expire_time > ?", keys, time.Now()).Find(&rows).Error; err != nil {
+		for _, op := range ops {
+			op.cmd.err = err
+		}
+		return err
+	}
+
+	byKey := make(map[string]string, len(rows))
+	for _, row := range rows {
+		byKey[row.CacheKey] = string(row.CacheValue)
+	}
+
+	for _, op := range ops {
+		if val, ok := byKey[op.key]; ok {
+			op.cmd.val = val
+		} else {
+			op.cmd.err = cache.ErrNotFound
+		}
+	}
+	return nil
+}
+
+// flushPipeDel 先查出待删 key 中哪些真实存在，删除后再按每个 Del 调用各自的 key
+// 列表统计各自删掉了几个，这样多个 Del 调用共用同一次 SELECT + DELETE。
+func flushPipeDel(tx *gorm.DB, ops []*pipeKeysOp) error {
+	existingSet, allKeys, err := existingKeys(tx, ops)
+	if err != nil {
+		for _, op := range ops {
+			op.cmd.err = err
+		}
+		return err
+	}
+
+	if err := tx.Where("cache_key IN ?", allKeys).Delete(&CacheKV{}).Error; err != nil {
+		for _, op := range ops {
+			op.cmd.err = err
+		}
+		return err
+	}
+
+	for _, op := range ops {
+		var n int64
+		for _, k := range op.keys {
+			if existingSet[k] {
+				n++
+			}
+		}
+		op.cmd.val = n
+	}
+	return nil
+}
+
+func flushPipeExists(tx *gorm.DB, ops []*pipeKeysOp) error {
+	existingSet, _, err := existingKeys(tx, ops)
+	if err != nil {
+		for _, op := range ops {
+			op.cmd.err = err
+		}
+		return err
+	}
+
+	for _, op := range ops {
+		var n int64
+		for _, k := range op.keys {
+			if existingSet[k] {
+				n++
+			}
+		}
+		op.cmd.val = n
+	}
+	return nil
+}
+
+// existingKeys returns which of the union of every op's keys currently exist
+// (and are unexpired) in cache_kvs, plus that de-duplicated union.
+func existingKeys(tx *gorm.DB, ops []*pipeKeysOp) (map[string]bool, []string, error) {
+	keySet := map[string]bool{}
+	for _, op := range ops {
+		for _, k := range op.keys {
+			keySet[k] = true
+		}
+	}
+	allKeys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		allKeys = append(allKeys, k)
+	}
+
+	var existing []string
+	err := tx.Model(&CacheKV{}).Where("cache_key IN ? AND expire_time > ?", allKeys, time.Now()).
+		Pluck("cache_key", &existing).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existingSet := make(map[string]bool, len(existing))
+	for _, k := range existing {
+		existingSet[k] = true
+	}
+	return existingSet, allKeys, nil
+}
+
+func flushPipeHSet(tx *gorm.DB, ops []*pipeHSetOp) error {
+	valuesSQL := ""
+	args := make([]interface{}, 0)
+	rowCounts := make([]int, len(ops))
+	for oi, op := range ops {
+		rowCounts[oi] = len(op.fields)
+		for i := range op.fields {
+			if len(args) > 0 {
+				valuesSQL += ", "
+			}
+			valuesSQL += "(?, ?, ?, NOW(), NOW())"
+			args = append(args, op.key, op.fields[i], op.values[i])
+		}
+	}
+
+	sql := fmt.Sprintf(`INSERT INTO cache_maps (cache_key, cache_field, cache_value, created_at, updated_at)
+		VALUES %s
+		ON DUPLICATE KEY UPDATE
+		cache_value = VALUES(cache_value),
+		updated_at = NOW()`, valuesSQL)
+
+	err := tx.Exec(sql, args...).Error
+	for i, op := range ops {
+		if err != nil {
+			op.cmd.err = err
+			continue
+		}
+		op.cmd.val = int64(rowCounts[i])
+	}
+	return err
+}
+
+func flushPipeHGetAll(tx *gorm.DB, ops []*pipeHGetOp) error {
+	keys := make([]string, len(ops))
+	for i, op := range ops {
+		keys[i] = op.key
+	}
+
+	var rows []CacheMap
+	if err := tx.Where("cache_key IN ?", keys).Find(&rows).Error; err != nil {
+		for _, op := range ops {
+			op.cmd.err = err
+		}
+		return err
+	}
+
+	byKey := make(map[string]map[string]string, len(ops))
+	for _, row := range rows {
+		m, ok := byKey[row.CacheKey]
+		if !ok {
+			m = make(map[string]string)
+			byKey[row.CacheKey] = m
+		}
+		m[row.CacheField] = row.CacheValue
+	}
+
+	for _, op := range ops {
+		if m, ok := byKey[op.key]; ok {
+			op.cmd.val = m
+		} else {
+			op.cmd.err = cache.ErrNotFound
+		}
+	}
+	return nil
+}
+
+// flushPipeExpire batches heterogeneous per-key expiry updates into a single
+// UPDATE ... CASE WHEN ... END, since a plain WHERE key IN (?) can't carry a
+// different expire_time per key.
+func flushPipeExpire(tx *gorm.DB, ops []*pipeExpireOp) error {
+	caseSQL := "CASE cache_key "
+	args := make([]interface{}, 0, len(ops)*2)
+	keys := make([]string, len(ops))
+	for i, op := range ops {
+		caseSQL += "WHEN ? THEN ? "
+		args = append(args, op.key, expireTimeFor(op.expire))
+		keys[i] = op.key
+	}
+	caseSQL += "END"
+	args = append(args, keys)
+
+	sql := fmt.Sprintf("UPDATE cache_kvs SET expire_time = %s WHERE cache_key IN ?", caseSQL)
+
+	result := tx.Exec(sql, args...)
+	for _, op := range ops {
+		op.cmd.err = result.Error
+		op.cmd.val = result.Error == nil
+	}
+	return result.Error
+}
+
+// flushPipeIncrBy batches the same atomic-upsert used by the non-pipelined
+// IncrBy (see atomicIncrBy) into one multi-row INSERT, then reads back every
+// key's new value in one SELECT. pipeIncrOp.delta is always an int64 (there
+// is no pipelined IncrByFloat), so this casts through SIGNED, not DECIMAL -
+// CAST(... AS DECIMAL(65,20)) then back to CHAR prints a fixed-point string
+// like "5.00000000000000000000", which strconv.ParseInt below can't read.
+func flushPipeIncrBy(tx *gorm.DB, ops []*pipeIncrOp) error {
+	valuesSQL := ""
+	args := make([]interface{}, 0, len(ops)*3)
+	for i, op := range ops {
+		if i > 0 {
+			valuesSQL += ", "
+		}
+		valuesSQL += "(?, ?, ?, NOW(), NOW())"
+		args = append(args, op.key, []byte(strconv.FormatInt(op.delta, 10)), time.Now().Add(noExpirySentinel))
+	}
+
+	sql := fmt.Sprintf(`INSERT INTO cache_kvs (cache_key, cache_value, expire_time, created_at, updated_at)
+		VALUES %s
+		ON DUPLICATE KEY UPDATE
+		cache_value = CAST(CAST(cache_value AS SIGNED) + CAST(VALUES(cache_value) AS SIGNED) AS CHAR),
+		updated_at = NOW()`, valuesSQL)
+
+	if err := tx.Exec(sql, args...).Error; err != nil {
+		for _, op := range ops {
+			op.cmd.err = err
+		}
+		return err
+	}
+
+	keys := make([]string, len(ops))
+	for i, op := range ops {
+		keys[i] = op.key
+	}
+
+	var rows []CacheKV
+	if err := tx.Where("cache_key IN ?", keys).Find(&rows).Error; err != nil {
+		for _, op := range ops {
+			op.cmd.err = err
+		}
+		return err
+	}
+
+	byKey := make(map[string]string, len(rows))
+	for _, row := range rows {
+		byKey[row.CacheKey] = string(row.CacheValue)
+	}
+
+	for _, op := range ops {
+		val, ok := byKey[op.key]
+		if !ok {
+			op.cmd.err = cache.ErrNotFound
+			continue
+		}
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			op.cmd.err = err
+			continue
+		}
+		op.cmd.val = n
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+	"github.com/coze-dev/coze-studio/backend/pkg/logs"
+)
+
+const (
+	// pubSubPollInterval is the base long-poll interval for a subscription;
+	// each poll jitters around it to avoid every subscriber hammering the
+	// Message table in lockstep (a thundering herd).
+	pubSubPollInterval = 200 * time.Millisecond
+	pubSubPollJitter   = 100 * time.Millisecond
+
+	// messageRetention bounds how long a published message is kept around
+	// for slow subscribers to catch up on before the reaper deletes it.
+	messageRetention = 24 * time.Hour
+
+	reaperInterval = 10 * time.Minute
+)
+
+// Publish 实现 PubSubCmdable 接口
+func (c *CmdableAdapter) Publish(ctx context.Context, channel string, payload any) cache.IntCmd {
+	err := c.client.Publish(channel, fmt.Sprint(payload))
+	if err != nil {
+		return &IntCmdAdapter{err: err}
+	}
+	return &IntCmdAdapter{val: 1}
+}
+
+// Subscribe 实现 PubSubCmdable 接口：订阅若干确定频道
+func (c *CmdableAdapter) Subscribe(ctx context.Context, channels ...string) cache.PubSub {
+	db := clientDB(c.client)
+	return newOceanBasePubSub(ctx, db, channels, nil)
+}
+
+// PSubscribe 实现 PubSubCmdable 接口：订阅若干 glob 模式（* 和 ? 通配符）
+func (c *CmdableAdapter) PSubscribe(ctx context.Context, patterns ...string) cache.PubSub {
+	db := clientDB(c.client)
+	return newOceanBasePubSub(ctx, db, nil, patterns)
+}
+
+// clientDB 从 cache.Client 中取出底层 *gorm.DB。CmdableAdapter 只与 OceanBase
+// 的 Client 实现搭配使用，所以这里直接做类型断言。
+func clientDB(client cache.Client) *gorm.DB {
+	if c, ok := client.(*Client); ok {
+		return c.DB
+	}
+	panic("oceanbase: CmdableAdapter used with a non-OceanBase cache.Client")
+}
+
+// oceanbasePubSub long-polls the Message table for either a fixed set of
+// channels or a set of glob patterns, and is the cache.PubSub returned by
+// Subscribe / PSubscribe.
+type oceanbasePubSub struct {
+	db       *gorm.DB
+	ch       chan *cache.PubSubMessage
+	cancel   context.CancelFunc
+	lastSeen int64
+}
+
+func newOceanBasePubSub(ctx context.Context, db *gorm.DB, channels, patterns []string) *oceanbasePubSub {
+	ctx, cancel := context.WithCancel(ctx)
+
+	p := &oceanbasePubSub{
+		db:     db,
+		ch:     make(chan *cache.PubSubMessage, 100),
+		cancel: cancel,
+	}
+
+	// 新订阅从此刻开始，不回放历史消息
+	var lastID int64
+	db.Model(&Message{}).Select("COALESCE(MAX(id), 0)").Scan(&lastID)
+	p.lastSeen = lastID
+
+	go p.loop(ctx, channels, patterns)
+
+	return p
+}
+
+func (p *oceanbasePubSub) loop(ctx context.Context, channels, patterns []string) {
+	defer close(p.ch)
+
+	likePatterns := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		likePatterns[i] = convertRegexToSQL(pattern)
+	}
+
+	// A fixed channel list can be registered with the local broker for an
+	// immediate same-process wake on Publish (see broker.go); a pattern
+	// subscription can't, since it's not tied to any specific channel name
+	// until a message actually arrives, so it relies on the poll ticker alone.
+	var wake <-chan struct{}
+	if len(channels) > 0 {
+		var cancelWake func()
+		wake, cancelWake = mergeWakes(channels)
+		defer cancelWake()
+	}
+
+	for {
+		jitter := time.Duration(rand.Int63n(int64(pubSubPollJitter)))
+		timer := time.NewTimer(pubSubPollInterval + jitter)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		var messages []Message
+		query := p.db.Where("id > ?", p.lastSeen)
+
+		switch {
+		case len(channels) > 0:
+			query = query.Where("channel IN ?", channels)
+		case len(likePatterns) > 0:
+			clauses := make([]string, len(likePatterns))
+			args := make([]interface{}, len(likePatterns))
+			for i, lp := range likePatterns {
+				clauses[i] = "channel LIKE ?"
+				args[i] = lp
+			}
+			query = query.Where(strings.Join(clauses, " OR "), args...)
+		default:
+			continue
+		}
+
+		if err := query.Order("id ASC").Limit(100).Find(&messages).Error; err != nil {
+			logs.Errorf("oceanbase pubsub poll failed: %v", err)
+			continue
+		}
+
+		for _, msg := range messages {
+			p.lastSeen = msg.ID
+
+			out := &cache.PubSubMessage{Channel: msg.Channel, Payload: msg.Message}
+			if len(patterns) > 0 {
+				out.Pattern = matchingPattern(msg.Channel, patterns)
+			}
+
+			select {
+			case p.ch <- out:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// matchingPattern returns the first glob pattern (from the original, non-SQL
+// form) that matches channel, for PubSubMessage.Pattern.
+func matchingPattern(channel string, patterns []string) string {
+	for _, pattern := range patterns {
+		sqlPattern := convertRegexToSQL(pattern)
+		if globLikeMatch(channel, sqlPattern) {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// globLikeMatch is a tiny SQL-LIKE matcher (% = any run, _ = any char) used
+// only to pick which original pattern produced a match, since the SQL query
+// itself already filtered by OR'd LIKE clauses.
+func globLikeMatch(s, likePattern string) bool {
+	parts := strings.Split(likePattern, "%")
+	idx := 0
+	for i, part := range parts {
+		part = strings.ReplaceAll(part, "_", "")
+		if part == "" {
+			continue
+		}
+		pos := strings.Index(s[idx:], part)
+		if pos < 0 {
+			return false
+		}
+		if i == 0 && pos != 0 {
+			return false
+		}
+		idx += pos + len(part)
+	}
+	return true
+}
+
+func (p *oceanbasePubSub) Channel() <-chan *cache.PubSubMessage {
+	return p.ch
+}
+
+func (p *oceanbasePubSub) ReceiveMessage(ctx context.Context) (*cache.PubSubMessage, error) {
+	select {
+	case msg, ok := <-p.ch:
+		if !ok {
+			return nil, cache.ErrNotFound
+		}
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *oceanbasePubSub) Close() error {
+	p.cancel()
+	return nil
+}
+
+// startMessageReaper periodically deletes Message rows older than
+// messageRetention, so a table that only ever grows via Publish doesn't grow
+// forever. For a channel with at least one active consumer group, a message
+// is only eligible once every group's last_delivered_id has advanced past
+// it (see consumergroup.go) as well as aged past messageRetention, so a slow
+// or stalled group can't have its backlog deleted out from under it;
+// channels with no consumer group at all keep the plain age-only rule this
+// reaper always used.
+func startMessageReaper(db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(reaperInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			reapExpiredMessages(db)
+			reapOrphanedGroupClaims(db)
+		}
+	}()
+}
+
+func reapExpiredMessages(db *gorm.DB) {
+	cutoff := time.Now().Add(-messageRetention)
+
+	var floors []struct {
+		Channel string
+		Floor   int64
+	}
+	if err := db.Model(&MessageGroupCursor{}).
+		Select("channel, MIN(last_delivered_id) as floor").
+		Group("channel").
+		Scan(&floors).Error; err != nil {
+		logs.Errorf("failed to compute consumer group floors: %v", err)
+		return
+	}
+
+	channelsWithGroups := make([]string, 0, len(floors))
+	var total int64
+	for _, f := range floors {
+		channelsWithGroups = append(channelsWithGroups, f.Channel)
+		result := db.Where("channel = ? AND id <= ? AND created_at < ?", f.Channel, f.Floor, cutoff).Delete(&Message{})
+		if result.Error != nil {
+			logs.Errorf("failed to reap expired pubsub messages on channel %s: %v", f.Channel, result.Error)
+			continue
+		}
+		total += result.RowsAffected
+	}
+
+	query := db.Where("created_at < ?", cutoff)
+	if len(channelsWithGroups) > 0 {
+		query = query.Where("channel NOT IN ?", channelsWithGroups)
+	}
+	result := query.Delete(&Message{})
+	if result.Error != nil {
+		logs.Errorf("failed to reap expired pubsub messages: %v", result.Error)
+		return
+	}
+	total += result.RowsAffected
+
+	if total > 0 {
+		logs.Infof("reaped %d expired pubsub messages", total)
+	}
+}
+
+// reapOrphanedGroupClaims deletes claim rows whose message has already been
+// reaped, so cache_message_group_claims doesn't keep growing for messages
+// that no longer exist.
+func reapOrphanedGroupClaims(db *gorm.DB) {
+	result := db.Exec(`DELETE c FROM cache_message_group_claims c
+		LEFT JOIN cache_messages m ON m.id = c.message_id
+		WHERE m.id IS NULL`)
+	if result.Error != nil {
+		logs.Errorf("failed to reap orphaned consumer group claims: %v", result.Error)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package respserver
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// readCommand reads one RESP2 array-of-bulk-strings command, the only
+// request shape real Redis clients (redis-cli, go-redis, BullMQ, ...) send.
+// Inline commands (a bare line with no leading '*') are not supported.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("respserver: expected array, got %q", line)
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("respserver: bad array length %q", line)
+	}
+
+	args := make([]string, n)
+	for i := 0; i < n; i++ {
+		bulk, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(bulk) == 0 || bulk[0] != '$' {
+			return nil, fmt.Errorf("respserver: expected bulk string, got %q", bulk)
+		}
+		size, err := strconv.Atoi(bulk[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("respserver: bad bulk length %q", bulk)
+		}
+
+		buf := make([]byte, size+2) // +2 for the trailing \r\n
+		if _, err := readFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func readFull(r *bufio.Reader, buf []byte) (int, error) {
+	total := 0
+	for total < len(buf) {
+		n, err := r.Read(buf[total:])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// writeSimpleString writes +OK-style replies.
+func writeSimpleString(w *bufio.Writer, s string) error {
+	_, err := fmt.Fprintf(w, "+%s\r\n", s)
+	return err
+}
+
+// writeError writes a RESP error reply. msg must not contain \r or \n.
+func writeError(w *bufio.Writer, msg string) error {
+	_, err := fmt.Fprintf(w, "-ERR %s\r\n", msg)
+	return err
+}
+
+func writeInteger(w *bufio.Writer, n int64) error {
+	_, err := fmt.Fprintf(w, ":%d\r\n", n)
+	return err
+}
+
+// writeBulkString writes a RESP bulk string, or a nil bulk string ("$-1\r\n")
+// when ok is false - the RESP2 encoding of a missing value, e.g. a GET miss.
+func writeBulkString(w *bufio.Writer, s string, ok bool) error {
+	if !ok {
+		_, err := w.WriteString("$-1\r\n")
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "$%d\r\n", len(s)); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(s); err != nil {
+		return err
+	}
+	_, err := w.WriteString("\r\n")
+	return err
+}
+
+// writeArrayHeader starts a RESP array reply of n elements; callers write the
+// n elements themselves with the other write* helpers.
+func writeArrayHeader(w *bufio.Writer, n int) error {
+	_, err := fmt.Fprintf(w, "*%d\r\n", n)
+	return err
+}
@@ -0,0 +1,492 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package respserver exposes a cache.Client over the RESP2 wire protocol, so
+// tools that only speak Redis (redis-cli, go-redis, BullMQ, ...) can talk to
+// the OceanBase-backed KV store without code changes on their side. It is a
+// thin protocol adapter: every command dispatches straight to the existing
+// cache.Client methods, it does not reimplement any storage logic of its own.
+package respserver
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+	"github.com/coze-dev/coze-studio/backend/pkg/logs"
+)
+
+// Server is a RESP2 listener in front of a cache.Client. The zero value is
+// not usable; build one with New.
+type Server struct {
+	client cache.Client
+	ln     net.Listener
+}
+
+// New builds a Server that dispatches RESP commands to client. Call
+// ListenAndServe to start accepting connections.
+func New(client cache.Client) *Server {
+	return &Server{client: client}
+}
+
+// ListenAndServe listens on addr (e.g. ":6380") and serves RESP2 connections
+// until the listener is closed. It blocks, so callers typically run it in its
+// own goroutine.
+func (s *Server) ListenAndServe(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	logs.Infof("oceanbase respserver listening on %s", addr)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// Close stops accepting new connections. Connections already being served
+// finish on their own once their client disconnects.
+func (s *Server) Close() error {
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Close()
+}
+
+// connState holds the per-connection subscribe fan-in: SUBSCRIBE can be
+// issued repeatedly on the same connection, and every channel's messages
+// have to land on the same socket without interleaving mid-frame, hence the
+// write mutex. subs is only ever read/written from handleConn's single read
+// loop (SUBSCRIBE/UNSUBSCRIBE run there, synchronously, one command at a
+// time), so it needs no lock of its own.
+type connState struct {
+	conn    net.Conn
+	w       *bufio.Writer
+	writeMu sync.Mutex
+	subs    map[string]func()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	cs := &connState{conn: conn, w: bufio.NewWriter(conn), subs: map[string]func(){}}
+	defer func() {
+		for _, cancel := range cs.subs {
+			cancel()
+		}
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			// The client disconnected (or the socket otherwise broke) -
+			// the deferred cancel above unsubscribes from cache.Client for
+			// every channel this connection was still subscribed to, which
+			// lets each forwardMessages goroutine below see its channel
+			// close and return instead of leaking for the life of the
+			// process.
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		if err := s.dispatch(cs, args); err != nil {
+			return
+		}
+	}
+}
+
+func (cs *connState) flush(fn func(w *bufio.Writer) error) error {
+	cs.writeMu.Lock()
+	defer cs.writeMu.Unlock()
+	if err := fn(cs.w); err != nil {
+		return err
+	}
+	return cs.w.Flush()
+}
+
+// dispatch runs one command against s.client and writes its RESP reply. The
+// only error it returns is a write/transport failure on cs.conn - command
+// errors (bad arity, cache miss, ...) are reported to the client as a RESP
+// error or nil reply, not by returning from dispatch.
+func (s *Server) dispatch(cs *connState, args []string) error {
+	name := strings.ToUpper(args[0])
+	args = args[1:]
+
+	switch name {
+	case "PING":
+		return cs.flush(func(w *bufio.Writer) error { return writeSimpleString(w, "PONG") })
+
+	case "GET":
+		if len(args) != 1 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'get'") })
+		}
+		val, err := s.client.GetBytes(args[0])
+		if err == cache.ErrNotFound {
+			return cs.flush(func(w *bufio.Writer) error { return writeBulkString(w, "", false) })
+		}
+		if err != nil {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+		}
+		return cs.flush(func(w *bufio.Writer) error { return writeBulkString(w, string(val), true) })
+
+	case "SET":
+		if len(args) < 2 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'set'") })
+		}
+		expire, err := parseSetExpiry(args[2:])
+		if err != nil {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+		}
+		if err := s.client.Set(args[0], args[1], expire); err != nil {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+		}
+		return cs.flush(func(w *bufio.Writer) error { return writeSimpleString(w, "OK") })
+
+	case "SETNX":
+		if len(args) != 2 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'setnx'") })
+		}
+		ok, err := s.client.SetNX(args[0], args[1], 0)
+		if err != nil {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+		}
+		return cs.flush(func(w *bufio.Writer) error { return writeInteger(w, boolToInt(ok)) })
+
+	case "EXPIRE":
+		if len(args) != 2 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'expire'") })
+		}
+		seconds, err := strconv.Atoi(args[1])
+		if err != nil {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "value is not an integer or out of range") })
+		}
+		ok, err := s.client.Expire(args[0], time.Duration(seconds)*time.Second)
+		if err != nil {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+		}
+		return cs.flush(func(w *bufio.Writer) error { return writeInteger(w, boolToInt(ok)) })
+
+	case "DEL":
+		if len(args) == 0 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'del'") })
+		}
+		var n int64
+		for _, key := range args {
+			affected, err := s.client.Delete(key)
+			if err != nil {
+				return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+			}
+			n += affected
+		}
+		return cs.flush(func(w *bufio.Writer) error { return writeInteger(w, n) })
+
+	case "HSET":
+		if len(args) < 3 || len(args)%2 != 1 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'hset'") })
+		}
+		key := args[0]
+		pairs := args[1:]
+		for i := 0; i < len(pairs); i += 2 {
+			if err := s.client.SetMapField(key, pairs[i], pairs[i+1]); err != nil {
+				return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+			}
+		}
+		// Client.SetMapField doesn't report created-vs-updated per field, so
+		// unlike real Redis HSET this always reports every field as newly
+		// set rather than only the fields that didn't already exist.
+		return cs.flush(func(w *bufio.Writer) error { return writeInteger(w, int64(len(pairs)/2)) })
+
+	case "HGET":
+		if len(args) != 2 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'hget'") })
+		}
+		val, err := s.client.GetMapField(args[0], args[1])
+		if err == cache.ErrNotFound {
+			return cs.flush(func(w *bufio.Writer) error { return writeBulkString(w, "", false) })
+		}
+		if err != nil {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+		}
+		return cs.flush(func(w *bufio.Writer) error { return writeBulkString(w, val, true) })
+
+	case "HGETALL":
+		if len(args) != 1 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'hgetall'") })
+		}
+		m, err := s.client.GetMap(args[0])
+		if err == cache.ErrNotFound {
+			return cs.flush(func(w *bufio.Writer) error { return writeArrayHeader(w, 0) })
+		}
+		if err != nil {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+		}
+		return cs.flush(func(w *bufio.Writer) error {
+			if err := writeArrayHeader(w, len(m)*2); err != nil {
+				return err
+			}
+			for field, val := range m {
+				if err := writeBulkString(w, field, true); err != nil {
+					return err
+				}
+				if err := writeBulkString(w, val, true); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	case "HSCAN":
+		if len(args) < 2 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'hscan'") })
+		}
+		return s.hscan(cs, args)
+
+	case "PUBLISH":
+		if len(args) != 2 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'publish'") })
+		}
+		if err := s.client.Publish(args[0], args[1]); err != nil {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+		}
+		// cache.Client has no way to report how many subscribers received
+		// the message (unlike go-redis's PUBLISH reply), so this always
+		// reports 0 rather than guessing.
+		return cs.flush(func(w *bufio.Writer) error { return writeInteger(w, 0) })
+
+	case "SUBSCRIBE":
+		if len(args) == 0 {
+			return cs.flush(func(w *bufio.Writer) error { return writeError(w, "wrong number of arguments for 'subscribe'") })
+		}
+		return s.subscribe(cs, args)
+
+	case "UNSUBSCRIBE":
+		return s.unsubscribe(cs, args)
+
+	default:
+		return cs.flush(func(w *bufio.Writer) error { return writeError(w, "unknown command '"+name+"'") })
+	}
+}
+
+// hscan maps to Client.ScanMapStream, which already returns field/value pairs
+// interleaved the way HSCAN's reply array does.
+func (s *Server) hscan(cs *connState, args []string) error {
+	key := args[0]
+	cursor, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return cs.flush(func(w *bufio.Writer) error { return writeError(w, "invalid cursor") })
+	}
+
+	match := ""
+	count := int64(10)
+	for i := 2; i < len(args); i += 2 {
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			if i+1 < len(args) {
+				match = args[i+1]
+			}
+		case "COUNT":
+			if i+1 < len(args) {
+				if c, err := strconv.ParseInt(args[i+1], 10, 64); err == nil {
+					count = c
+				}
+			}
+		}
+	}
+
+	fieldsAndValues, next, err := s.client.ScanMapStream(key, cursor, match, count)
+	if err != nil {
+		return cs.flush(func(w *bufio.Writer) error { return writeError(w, err.Error()) })
+	}
+
+	return cs.flush(func(w *bufio.Writer) error {
+		if err := writeArrayHeader(w, 2); err != nil {
+			return err
+		}
+		if err := writeBulkString(w, strconv.FormatUint(next, 10), true); err != nil {
+			return err
+		}
+		if err := writeArrayHeader(w, len(fieldsAndValues)); err != nil {
+			return err
+		}
+		for _, v := range fieldsAndValues {
+			if err := writeBulkString(w, v, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// subscribe acknowledges each requested channel the way real Redis does,
+// then returns immediately - it does not block the connection. Each
+// channel's messages are forwarded to cs.conn by its own background
+// goroutine (see forwardMessages), so handleConn's read loop keeps reading
+// further commands (more SUBSCRIBE/UNSUBSCRIBE, PING, ...) on the same
+// connection while subscribed, and - critically - keeps noticing when the
+// client disconnects instead of being stuck in a loop with nothing left to
+// read from.
+func (s *Server) subscribe(cs *connState, channels []string) error {
+	for _, channel := range channels {
+		if _, already := cs.subs[channel]; !already {
+			ch, cancel := s.client.Subscribe(channel)
+			cs.subs[channel] = cancel
+			go cs.forwardMessages(channel, ch)
+		}
+
+		err := cs.flush(func(w *bufio.Writer) error {
+			if err := writeArrayHeader(w, 3); err != nil {
+				return err
+			}
+			if err := writeBulkString(w, "subscribe", true); err != nil {
+				return err
+			}
+			if err := writeBulkString(w, channel, true); err != nil {
+				return err
+			}
+			return writeInteger(w, int64(len(cs.subs)))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unsubscribe cancels the given channels (or every channel this connection
+// is subscribed to, if none are named, same as real Redis UNSUBSCRIBE).
+// Canceling closes the channel forwardMessages is ranging over, so that
+// goroutine exits on its own once it drains whatever's already queued.
+func (s *Server) unsubscribe(cs *connState, channels []string) error {
+	if len(channels) == 0 {
+		for channel := range cs.subs {
+			channels = append(channels, channel)
+		}
+	}
+	if len(channels) == 0 {
+		// Real Redis still sends one reply here, with a nil channel, when
+		// UNSUBSCRIBE is issued with nothing currently subscribed.
+		return cs.flush(func(w *bufio.Writer) error {
+			if err := writeArrayHeader(w, 3); err != nil {
+				return err
+			}
+			if err := writeBulkString(w, "unsubscribe", true); err != nil {
+				return err
+			}
+			if err := writeBulkString(w, "", false); err != nil {
+				return err
+			}
+			return writeInteger(w, 0)
+		})
+	}
+
+	for _, channel := range channels {
+		if cancel, ok := cs.subs[channel]; ok {
+			cancel()
+			delete(cs.subs, channel)
+		}
+
+		err := cs.flush(func(w *bufio.Writer) error {
+			if err := writeArrayHeader(w, 3); err != nil {
+				return err
+			}
+			if err := writeBulkString(w, "unsubscribe", true); err != nil {
+				return err
+			}
+			if err := writeBulkString(w, channel, true); err != nil {
+				return err
+			}
+			return writeInteger(w, int64(len(cs.subs)))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forwardMessages pushes every message delivered on ch to cs.conn as a RESP
+// "message" frame, until ch is closed - which happens once the matching
+// cancel func in cs.subs is called, either by UNSUBSCRIBE or by handleConn's
+// disconnect cleanup.
+func (cs *connState) forwardMessages(channel string, ch <-chan string) {
+	for msg := range ch {
+		err := cs.flush(func(w *bufio.Writer) error {
+			if err := writeArrayHeader(w, 3); err != nil {
+				return err
+			}
+			if err := writeBulkString(w, "message", true); err != nil {
+				return err
+			}
+			if err := writeBulkString(w, channel, true); err != nil {
+				return err
+			}
+			return writeBulkString(w, msg, true)
+		})
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseSetExpiry parses SET's trailing [EX seconds | PX milliseconds]
+// option, returning 0 (no expiry) if none was given.
+func parseSetExpiry(opts []string) (time.Duration, error) {
+	if len(opts) == 0 {
+		return 0, nil
+	}
+	if len(opts) != 2 {
+		return 0, errInvalidSetOption
+	}
+
+	n, err := strconv.ParseInt(opts[1], 10, 64)
+	if err != nil {
+		return 0, errInvalidSetOption
+	}
+
+	switch strings.ToUpper(opts[0]) {
+	case "EX":
+		return time.Duration(n) * time.Second, nil
+	case "PX":
+		return time.Duration(n) * time.Millisecond, nil
+	default:
+		return 0, errInvalidSetOption
+	}
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var errInvalidSetOption = errInvalid("syntax error")
+
+type errInvalid string
+
+func (e errInvalid) Error() string { return string(e) }
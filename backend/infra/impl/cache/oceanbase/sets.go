@@ -0,0 +1,136 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+)
+
+// SAdd 实现SetCmdable接口，使用 INSERT IGNORE 避免重复成员触发唯一索引冲突
+func (c *CmdableAdapter) SAdd(ctx context.Context, key string, members ...interface{}) cache.IntCmd {
+	db := clientDB(c.client)
+
+	var added int64
+	for _, m := range members {
+		sql := `INSERT IGNORE INTO cache_sets (cache_key, member, expire_time, created_at, updated_at)
+			VALUES (?, ?, ?, NOW(), NOW())`
+		result := db.Exec(sql, key, fmt.Sprint(m), time.Now().Add(noExpirySentinel))
+		if result.Error != nil {
+			return &IntCmdAdapter{err: result.Error}
+		}
+		added += result.RowsAffected
+	}
+	return &IntCmdAdapter{val: added}
+}
+
+// SRem 实现SetCmdable接口
+func (c *CmdableAdapter) SRem(ctx context.Context, key string, members ...interface{}) cache.IntCmd {
+	db := clientDB(c.client)
+
+	memberStrs := make([]string, len(members))
+	for i, m := range members {
+		memberStrs[i] = fmt.Sprint(m)
+	}
+	result := db.Where("cache_key = ? AND member IN ?", key, memberStrs).Delete(&CacheSet{})
+	return &IntCmdAdapter{val: result.RowsAffected, err: result.Error}
+}
+
+// SMembers 实现SetCmdable接口
+func (c *CmdableAdapter) SMembers(ctx context.Context, key string) cache.StringSliceCmd {
+	db := clientDB(c.client)
+
+	var rows []CacheSet
+	if err := db.Where("cache_key = ? AND expire_time > ?", key, time.Now()).Find(&rows).Error; err != nil {
+		return &StringSliceCmdAdapter{err: err}
+	}
+
+	vals := make([]string, len(rows))
+	for i, row := range rows {
+		vals[i] = row.Member
+	}
+	return &StringSliceCmdAdapter{val: vals}
+}
+
+// SIsMember 实现SetCmdable接口
+func (c *CmdableAdapter) SIsMember(ctx context.Context, key string, member interface{}) cache.BoolCmd {
+	db := clientDB(c.client)
+
+	var count int64
+	err := db.Model(&CacheSet{}).
+		Where("cache_key = ? AND member = ? AND expire_time > ?", key, fmt.Sprint(member), time.Now()).
+		Count(&count).Error
+	return &BoolCmdAdapter{val: count > 0, err: err}
+}
+
+// SCard 实现SetCmdable接口
+func (c *CmdableAdapter) SCard(ctx context.Context, key string) cache.IntCmd {
+	db := clientDB(c.client)
+
+	var count int64
+	err := db.Model(&CacheSet{}).Where("cache_key = ? AND expire_time > ?", key, time.Now()).Count(&count).Error
+	return &IntCmdAdapter{val: count, err: err}
+}
+
+// SInter 实现SetCmdable接口：通过对 cache_sets 自身按 member 做多次 JOIN 求交集，
+// 而不是把每个 key 的成员都取回内存再比较。
+func (c *CmdableAdapter) SInter(ctx context.Context, keys ...string) cache.StringSliceCmd {
+	if len(keys) == 0 {
+		return &StringSliceCmdAdapter{val: []string{}}
+	}
+	db := clientDB(c.client)
+
+	query := db.Table("cache_sets AS s0").
+		Where("s0.cache_key = ? AND s0.expire_time > ?", keys[0], time.Now()).
+		Select("s0.member")
+
+	for i, key := range keys[1:] {
+		alias := fmt.Sprintf("s%d", i+1)
+		query = query.Joins(
+			fmt.Sprintf("JOIN cache_sets AS %s ON %s.member = s0.member AND %s.cache_key = ? AND %s.expire_time > ?",
+				alias, alias, alias, alias),
+			key, time.Now(),
+		)
+	}
+
+	var members []string
+	if err := query.Scan(&members).Error; err != nil {
+		return &StringSliceCmdAdapter{err: err}
+	}
+	return &StringSliceCmdAdapter{val: members}
+}
+
+// SUnion 实现SetCmdable接口
+func (c *CmdableAdapter) SUnion(ctx context.Context, keys ...string) cache.StringSliceCmd {
+	if len(keys) == 0 {
+		return &StringSliceCmdAdapter{val: []string{}}
+	}
+	db := clientDB(c.client)
+
+	var members []string
+	err := db.Model(&CacheSet{}).
+		Where("cache_key IN ? AND expire_time > ?", keys, time.Now()).
+		Distinct("member").
+		Pluck("member", &members).Error
+	if err != nil {
+		return &StringSliceCmdAdapter{err: err}
+	}
+	return &StringSliceCmdAdapter{val: members}
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/pkg/logs"
+)
+
+const (
+	// defaultSweepInterval is how often the active-expire loop wakes up to
+	// look for stale rows, mirroring Redis's periodic active-expire cycle.
+	defaultSweepInterval = time.Second
+
+	// sweepSampleSize caps how many expired rows are deleted per table in one
+	// pass, so a table with a large backlog of expired rows is cleaned up
+	// incrementally instead of via one long table scan.
+	sweepSampleSize = 20
+
+	// sweepHitRatio is the fraction of sweepSampleSize that must have been
+	// expired for the sweeper to immediately run another pass on that table
+	// instead of waiting for the next tick, the same way Redis keeps
+	// expiring while more than 25% of its sample is stale.
+	sweepHitRatio = 0.25
+)
+
+// sweepInterval is overridable for callers that want a different cadence
+// than defaultSweepInterval; set it before calling InitOceanBaseCache.
+var sweepInterval = defaultSweepInterval
+
+// SetExpirySweepInterval configures how often the active-expire sweeper
+// runs. It must be called before InitOceanBaseCache / InitOceanBaseClient.
+func SetExpirySweepInterval(interval time.Duration) {
+	sweepInterval = interval
+}
+
+// startExpirySweeper launches the background goroutine that actively deletes
+// expired rows, instead of relying solely on lazy expiry at read time. This
+// keeps tables from growing unboundedly with rows nobody ever reads again.
+func startExpirySweeper(db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			sweepExpiredOnce(db)
+		}
+	}()
+}
+
+// sweepExpiredOnce runs one active-expire pass over every table that carries
+// an expire_time column. For each table it keeps deleting sweepSampleSize
+// rows at a time while the hit ratio stays high, then moves on, so a single
+// tick doesn't starve the other tables if one of them has a large backlog.
+func sweepExpiredOnce(db *gorm.DB) {
+	sweeps := []struct {
+		name  string
+		model interface{}
+	}{
+		{"cache_kvs", &CacheKV{}},
+		{"cache_lists", &CacheList{}},
+		{"cache_sets", &CacheSet{}},
+		{"cache_zsets", &CacheZSet{}},
+	}
+
+	for _, s := range sweeps {
+		for {
+			result := db.Where("expire_time < ?", time.Now()).Limit(sweepSampleSize).Delete(s.model)
+			if result.Error != nil {
+				logs.Errorf("expiry sweeper failed on %s: %v", s.name, result.Error)
+				break
+			}
+			if result.RowsAffected == 0 {
+				break
+			}
+			if float64(result.RowsAffected)/float64(sweepSampleSize) < sweepHitRatio {
+				break
+			}
+		}
+	}
+}
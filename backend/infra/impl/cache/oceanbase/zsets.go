@@ -0,0 +1,188 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/cache"
+)
+
+// ZAdd 实现ZSetCmdable接口，使用 INSERT ... ON DUPLICATE KEY UPDATE 对已存在的成员更新分数
+func (c *CmdableAdapter) ZAdd(ctx context.Context, key string, members ...cache.Z) cache.IntCmd {
+	db := clientDB(c.client)
+
+	var added int64
+	for _, z := range members {
+		sql := `INSERT INTO cache_zsets (cache_key, member, score, expire_time, created_at, updated_at)
+			VALUES (?, ?, ?, ?, NOW(), NOW())
+			ON DUPLICATE KEY UPDATE
+			score = VALUES(score),
+			updated_at = NOW()`
+		result := db.Exec(sql, key, fmt.Sprint(z.Member), z.Score, time.Now().Add(noExpirySentinel))
+		if result.Error != nil {
+			return &IntCmdAdapter{err: result.Error}
+		}
+		added += result.RowsAffected
+	}
+	return &IntCmdAdapter{val: added}
+}
+
+// ZRem 实现ZSetCmdable接口
+func (c *CmdableAdapter) ZRem(ctx context.Context, key string, members ...interface{}) cache.IntCmd {
+	db := clientDB(c.client)
+
+	memberStrs := make([]string, len(members))
+	for i, m := range members {
+		memberStrs[i] = fmt.Sprint(m)
+	}
+	result := db.Where("cache_key = ? AND member IN ?", key, memberStrs).Delete(&CacheZSet{})
+	return &IntCmdAdapter{val: result.RowsAffected, err: result.Error}
+}
+
+// ZCard 实现ZSetCmdable接口
+func (c *CmdableAdapter) ZCard(ctx context.Context, key string) cache.IntCmd {
+	db := clientDB(c.client)
+
+	var count int64
+	err := db.Model(&CacheZSet{}).Where("cache_key = ? AND expire_time > ?", key, time.Now()).Count(&count).Error
+	return &IntCmdAdapter{val: count, err: err}
+}
+
+// ZScore 实现ZSetCmdable接口
+func (c *CmdableAdapter) ZScore(ctx context.Context, key string, member string) cache.FloatCmd {
+	db := clientDB(c.client)
+
+	var row CacheZSet
+	err := db.Where("cache_key = ? AND member = ? AND expire_time > ?", key, member, time.Now()).First(&row).Error
+	if err != nil {
+		return &FloatCmdAdapter{err: cache.ErrNotFound}
+	}
+	return &FloatCmdAdapter{val: row.Score}
+}
+
+// ZIncrBy 实现ZSetCmdable接口，使用原子 UPSERT 而不是读-改-写
+func (c *CmdableAdapter) ZIncrBy(ctx context.Context, key string, increment float64, member string) cache.FloatCmd {
+	db := clientDB(c.client)
+
+	var score float64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		sql := `INSERT INTO cache_zsets (cache_key, member, score, expire_time, created_at, updated_at)
+			VALUES (?, ?, ?, ?, NOW(), NOW())
+			ON DUPLICATE KEY UPDATE
+			score = score + VALUES(score),
+			updated_at = NOW()`
+		if err := tx.Exec(sql, key, member, increment, time.Now().Add(noExpirySentinel)).Error; err != nil {
+			return err
+		}
+
+		var row CacheZSet
+		if err := tx.Where("cache_key = ? AND member = ?", key, member).First(&row).Error; err != nil {
+			return err
+		}
+		score = row.Score
+		return nil
+	})
+	if err != nil {
+		return &FloatCmdAdapter{err: err}
+	}
+	return &FloatCmdAdapter{val: score}
+}
+
+// ZRank 实现ZSetCmdable接口：按 (score, member) 排序，统计排在目标成员之前的行数得到名次
+func (c *CmdableAdapter) ZRank(ctx context.Context, key string, member string) cache.IntCmd {
+	db := clientDB(c.client)
+
+	var target CacheZSet
+	if err := db.Where("cache_key = ? AND member = ? AND expire_time > ?", key, member, time.Now()).First(&target).Error; err != nil {
+		return &IntCmdAdapter{err: cache.ErrNotFound}
+	}
+
+	var rank int64
+	err := db.Model(&CacheZSet{}).
+		Where("cache_key = ? AND expire_time > ? AND (score < ? OR (score = ? AND member < ?))",
+			key, time.Now(), target.Score, target.Score, member).
+		Count(&rank).Error
+	if err != nil {
+		return &IntCmdAdapter{err: err}
+	}
+	return &IntCmdAdapter{val: rank}
+}
+
+// ZRange 实现ZSetCmdable接口，start/stop 为按分数升序排列后的名次，支持redis风格的负数下标
+func (c *CmdableAdapter) ZRange(ctx context.Context, key string, start, stop int64) cache.StringSliceCmd {
+	db := clientDB(c.client)
+
+	var count int64
+	if err := db.Model(&CacheZSet{}).Where("cache_key = ? AND expire_time > ?", key, time.Now()).Count(&count).Error; err != nil {
+		return &StringSliceCmdAdapter{err: err}
+	}
+	if count == 0 {
+		return &StringSliceCmdAdapter{val: []string{}}
+	}
+
+	if start < 0 {
+		start = count + start
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop < 0 {
+		stop = count + stop
+	}
+	if stop >= count {
+		stop = count - 1
+	}
+	if start > stop || start >= count {
+		return &StringSliceCmdAdapter{val: []string{}}
+	}
+
+	var rows []CacheZSet
+	err := db.Where("cache_key = ? AND expire_time > ?", key, time.Now()).
+		Order("score ASC, member ASC").Offset(int(start)).Limit(int(stop - start + 1)).Find(&rows).Error
+	if err != nil {
+		return &StringSliceCmdAdapter{err: err}
+	}
+
+	vals := make([]string, len(rows))
+	for i, row := range rows {
+		vals[i] = row.Member
+	}
+	return &StringSliceCmdAdapter{val: vals}
+}
+
+// ZRangeByScore 实现ZSetCmdable接口，返回分数在 [min, max] 区间内的成员，按分数升序排列
+func (c *CmdableAdapter) ZRangeByScore(ctx context.Context, key string, min, max float64) cache.StringSliceCmd {
+	db := clientDB(c.client)
+
+	var rows []CacheZSet
+	err := db.Where("cache_key = ? AND score >= ? AND score <= ? AND expire_time > ?", key, min, max, time.Now()).
+		Order("score ASC, member ASC").Find(&rows).Error
+	if err != nil {
+		return &StringSliceCmdAdapter{err: err}
+	}
+
+	vals := make([]string, len(rows))
+	for i, row := range rows {
+		vals[i] = row.Member
+	}
+	return &StringSliceCmdAdapter{val: vals}
+}
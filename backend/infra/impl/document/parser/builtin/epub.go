@@ -0,0 +1,171 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package builtin
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/parser"
+)
+
+// epubContainer is META-INF/container.xml, the one file an EPUB zip
+// guarantees the path of; it only exists to point at the real package
+// document (the OPF file), whose own path and name vary between books.
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the OPF package document. manifest maps an item id to the
+// zip-relative file that holds it; spine lists those ids in reading order,
+// which is the order chapters must be concatenated in - directory listing
+// order inside the zip is not guaranteed to match it.
+type epubPackage struct {
+	Manifest struct {
+		Items []struct {
+			ID   string `xml:"id,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"item"`
+	} `xml:"manifest"`
+	Spine struct {
+		ItemRefs []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+// ParseEPUB解析EPUB：EPUB本质上是一个zip包，META-INF/container.xml指向
+// 真正的OPF package document，OPF的manifest把id映射到zip内的(X)HTML文件，
+// spine按阅读顺序列出这些id。按spine顺序读取每一章节的(X)HTML，用goquery
+// 去掉标签只留正文，每章节生成一个schema.Document，chapter序号写进Metadata
+// 方便后续按章节定位。
+func ParseEPUB(config *parser.Config) ParseFn {
+	return func(ctx context.Context, reader io.Reader) ([]*schema.Document, error) {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("[ParseEPUB] read failed, err=%w", err)
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("[ParseEPUB] not a valid epub/zip archive, err=%w", err)
+		}
+
+		opfPath, err := epubOPFPath(zr)
+		if err != nil {
+			return nil, err
+		}
+
+		pkg, err := epubReadPackage(zr, opfPath)
+		if err != nil {
+			return nil, err
+		}
+
+		hrefByID := make(map[string]string, len(pkg.Manifest.Items))
+		for _, item := range pkg.Manifest.Items {
+			hrefByID[item.ID] = item.Href
+		}
+
+		opfDir := path.Dir(opfPath)
+		docs := make([]*schema.Document, 0, len(pkg.Spine.ItemRefs))
+		for i, ref := range pkg.Spine.ItemRefs {
+			href, ok := hrefByID[ref.IDRef]
+			if !ok {
+				continue
+			}
+
+			text, err := epubChapterText(zr, path.Join(opfDir, href))
+			if err != nil {
+				return nil, fmt.Errorf("[ParseEPUB] chapter %q, err=%w", href, err)
+			}
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+
+			docs = append(docs, &schema.Document{
+				Content: text,
+				MetaData: map[string]any{
+					"chapter_index": i,
+					"chapter_href":  href,
+				},
+			})
+		}
+
+		return docs, nil
+	}
+}
+
+// epubOPFPath reads META-INF/container.xml to find the OPF package
+// document's path; every EPUB has this file at this fixed path by spec.
+func epubOPFPath(zr *zip.Reader) (string, error) {
+	f, err := zr.Open("META-INF/container.xml")
+	if err != nil {
+		return "", fmt.Errorf("[ParseEPUB] missing META-INF/container.xml, err=%w", err)
+	}
+	defer f.Close()
+
+	var container epubContainer
+	if err := xml.NewDecoder(f).Decode(&container); err != nil {
+		return "", fmt.Errorf("[ParseEPUB] invalid container.xml, err=%w", err)
+	}
+	if len(container.Rootfiles) == 0 {
+		return "", fmt.Errorf("[ParseEPUB] container.xml lists no rootfile")
+	}
+	return container.Rootfiles[0].FullPath, nil
+}
+
+func epubReadPackage(zr *zip.Reader, opfPath string) (*epubPackage, error) {
+	f, err := zr.Open(opfPath)
+	if err != nil {
+		return nil, fmt.Errorf("[ParseEPUB] missing OPF file %q, err=%w", opfPath, err)
+	}
+	defer f.Close()
+
+	var pkg epubPackage
+	if err := xml.NewDecoder(f).Decode(&pkg); err != nil {
+		return nil, fmt.Errorf("[ParseEPUB] invalid OPF file %q, err=%w", opfPath, err)
+	}
+	return &pkg, nil
+}
+
+// epubChapterText extracts the plain text of one spine item's (X)HTML,
+// stripping markup with goquery the same way ParseMarkdown strips rendered
+// HTML, so headings/paragraphs/lists all collapse down to their text.
+func epubChapterText(zr *zip.Reader, itemPath string) (string, error) {
+	f, err := zr.Open(itemPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(doc.Text()), nil
+}
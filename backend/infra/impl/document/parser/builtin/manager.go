@@ -62,6 +62,17 @@ func (m *manager) GetParser(config *parser.Config) (parser.Parser, error) {
 		pFn = ParseMarkdown(config, m.storage, m.ocr)
 	case parser.FileExtensionDocx:
 		pFn = ParseByPython(config, m.storage, m.ocr, goutil.GetPython3Path(), goutil.GetPythonFilePath("parse_docx.py"))
+	case parser.FileExtensionDoc:
+		// legacy binary .doc, needs its own extractor (antiword/python-docx can't read it)
+		pFn = ParseByPython(config, m.storage, m.ocr, goutil.GetPython3Path(), goutil.GetPythonFilePath("parse_doc.py"))
+	case parser.FileExtensionEPUB:
+		// Go-native: unzip + OPF spine traversal + goquery per chapter, see epub.go.
+		pFn = ParseEPUB(config)
+	case parser.FileExtensionMOBI:
+		pFn = ParseByPython(config, m.storage, m.ocr, goutil.GetPython3Path(), goutil.GetPythonFilePath("parse_mobi.py"))
+	case parser.FileExtensionRTF:
+		// Go-native control-word/group scanner, no external dependency, see rtf.go.
+		pFn = ParseRTF(config)
 	case parser.FileExtensionCSV:
 		pFn = ParseCSV(config)
 	case parser.FileExtensionXLSX:
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/parser"
+)
+
+// rtfSkipGroups不输出正文的控制字（字体表、颜色表、样式表、图片数据等），
+// 遇到这些分组名就跳过整个\{...\}分组，不把里面的控制字/参数当正文产出。
+var rtfSkipGroups = map[string]bool{
+	"fonttbl":    true,
+	"colortbl":   true,
+	"stylesheet": true,
+	"pict":       true,
+	"object":     true,
+	"info":       true,
+	"*":          true,
+}
+
+// ParseRTF用一个手写的RTF控制字/分组扫描器把标记去掉，只保留正文，不依赖
+// 外部库或解释器：RTF的数据模型足够简单（花括号分组 + \controlword[param]
+// + 纯文本 + \'hh十六进制转义），不需要完整的RTF渲染器就能抽出可读文本。
+func ParseRTF(config *parser.Config) ParseFn {
+	return func(ctx context.Context, reader io.Reader) ([]*schema.Document, error) {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("[ParseRTF] read failed, err=%w", err)
+		}
+
+		text, err := rtfToText(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("[ParseRTF] %w", err)
+		}
+
+		return []*schema.Document{{Content: text}}, nil
+	}
+}
+
+func rtfToText(src string) (string, error) {
+	var out strings.Builder
+	// skipUntilDepth>=0时当前处于一个rtfSkipGroups分组内部，连它嵌套的子分组一起
+	// 跳过；skipUntilDepth记录触发跳过的那一层花括号深度，深度回落到它以下
+	// 才恢复正常输出。
+	depth := 0
+	skipUntilDepth := -1
+
+	// ucStack holds the current \ucN value (RTF default 1) for each open
+	// brace group: \uc is scoped like any other formatting property, so a
+	// nested group inherits its enclosing group's value and restores it on
+	// '}'. It's the number of plain-text fallback characters that follow
+	// every \u escape for old, Unicode-unaware readers and must be swallowed
+	// rather than emitted.
+	ucStack := []int{1}
+	// ucSkip counts down the fallback characters still owed after the most
+	// recent \u escape.
+	ucSkip := 0
+
+	runes := []rune(src)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case '{':
+			depth++
+			ucStack = append(ucStack, ucStack[len(ucStack)-1])
+		case '}':
+			depth--
+			if len(ucStack) > 1 {
+				ucStack = ucStack[:len(ucStack)-1]
+			}
+			if skipUntilDepth >= 0 && depth < skipUntilDepth {
+				skipUntilDepth = -1
+			}
+		case '\\':
+			word, arg, consumed, err := rtfReadControl(runes, i+1)
+			if err != nil {
+				return "", err
+			}
+			i += consumed
+			if skipUntilDepth >= 0 {
+				continue
+			}
+			switch word {
+			case "par", "line":
+				out.WriteByte('\n')
+			case "tab":
+				out.WriteByte('\t')
+			case "uc":
+				if n, err := strconv.Atoi(arg); err == nil && n >= 0 {
+					ucStack[len(ucStack)-1] = n
+				}
+			case "u":
+				if arg != "" {
+					if code, err := strconv.Atoi(arg); err == nil {
+						out.WriteRune(utf16.Decode([]uint16{uint16(int16(code))})[0])
+					}
+				}
+				ucSkip = ucStack[len(ucStack)-1]
+			case "'":
+				// \'hh is a hex-escaped byte from the document's 8-bit
+				// codepage; treat it as Latin-1 since RTF gives no other
+				// signal about which codepage without parsing \ansicpg.
+				if len(arg) == 2 {
+					if b, err := strconv.ParseUint(arg, 16, 8); err == nil {
+						out.WriteRune(rune(b))
+					}
+				}
+			default:
+				if rtfSkipGroups[word] {
+					skipUntilDepth = depth
+				}
+			}
+		default:
+			if skipUntilDepth < 0 {
+				if ucSkip > 0 {
+					ucSkip--
+				} else {
+					out.WriteRune(r)
+				}
+			}
+		}
+	}
+
+	return strings.Join(strings.Fields(out.String()), " "), nil
+}
+
+// rtfReadControl reads one \controlword, an optional signed numeric
+// parameter, and the single trailing space RTF uses as a delimiter (which is
+// consumed, not emitted as text). It returns how many runes past the
+// leading backslash were consumed, so the caller can advance its index.
+func rtfReadControl(runes []rune, start int) (word string, arg string, consumed int, err error) {
+	i := start
+	if i >= len(runes) {
+		return "", "", 0, fmt.Errorf("truncated control word")
+	}
+
+	// \'hh and single-char escapes like \{ \} \\ are their own control word.
+	if runes[i] == '\'' {
+		if i+2 >= len(runes) {
+			return "", "", 0, fmt.Errorf("truncated hex escape")
+		}
+		return "'", string(runes[i+1 : i+3]), i + 3 - start, nil
+	}
+	if !isRTFAlpha(runes[i]) {
+		return string(runes[i]), "", i + 1 - start, nil
+	}
+
+	j := i
+	for j < len(runes) && isRTFAlpha(runes[j]) {
+		j++
+	}
+	word = string(runes[i:j])
+
+	k := j
+	if k < len(runes) && (runes[k] == '-' || isRTFDigit(runes[k])) {
+		d := k + 1
+		for d < len(runes) && isRTFDigit(runes[d]) {
+			d++
+		}
+		arg = string(runes[k:d])
+		k = d
+	}
+
+	if k < len(runes) && runes[k] == ' ' {
+		k++
+	}
+	return word, arg, k - start, nil
+}
+
+func isRTFAlpha(r rune) bool { return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' }
+func isRTFDigit(r rune) bool { return r >= '0' && r <= '9' }
@@ -0,0 +1,219 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package httpxenc implements rerank.Reranker against an HTTP cross-encoder
+// service (bge-reranker, Cohere Rerank, and similar all accept this
+// {query, documents[]} -> scores[] shape), batching large candidate sets into
+// several requests instead of one oversized call.
+package httpxenc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/rerank"
+)
+
+const (
+	defaultTimeout    = 5 * time.Second
+	defaultBatchSize  = 32
+	defaultMaxRetries = 2
+)
+
+type Config struct {
+	BaseURL    string        // required, POST {query, documents} -> {scores}
+	APIKey     string        // optional, sent as "Authorization: Bearer <key>"
+	Timeout    time.Duration // defaults to defaultTimeout
+	BatchSize  int           // defaults to defaultBatchSize
+	MaxRetries int           // negative falls back to defaultMaxRetries; 0 means no retry
+	TopN       int           // 0 means return all, reordered
+}
+
+type crossEncoderReranker struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewCrossEncoderReranker builds an HTTP cross-encoder rerank.Reranker.
+func NewCrossEncoderReranker(cfg Config) (rerank.Reranker, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("[NewCrossEncoderReranker] base_url not provided")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+
+	return &crossEncoderReranker{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// New adapts NewCrossEncoderReranker to rerank.Factory so it can be
+// registered in the reranker registry and selected by profile config
+// (type: http_cross_encoder).
+func New(cfg rerank.Config) (rerank.Reranker, error) {
+	c := Config{
+		BaseURL: cfg.Params["base_url"],
+		APIKey:  cfg.Params["api_key"],
+	}
+	if v, ok := cfg.Params["timeout_ms"]; ok {
+		if ms, err := strconv.Atoi(v); err == nil {
+			c.Timeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if v, ok := cfg.Params["batch_size"]; ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.BatchSize = n
+		}
+	}
+	if v, ok := cfg.Params["max_retries"]; ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.MaxRetries = n
+		}
+	}
+	if v, ok := cfg.Params["top_n"]; ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.TopN = n
+		}
+	}
+	return NewCrossEncoderReranker(c)
+}
+
+func init() {
+	rerank.Register("http_cross_encoder", rerank.FactoryFunc(New))
+}
+
+type scoreRequest struct {
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+type scoreResponse struct {
+	Scores []float64 `json:"scores"`
+}
+
+func (r *crossEncoderReranker) Rerank(ctx context.Context, query string, docs []*rerank.Document) ([]*rerank.Document, error) {
+	scored := make([]*rerank.Document, len(docs))
+	for start := 0; start < len(docs); start += r.cfg.BatchSize {
+		end := start + r.cfg.BatchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+
+		batch := docs[start:end]
+		scores, err := r.scoreBatch(ctx, query, batch)
+		if err != nil {
+			return nil, fmt.Errorf("[Rerank] score batch [%d:%d] failed, err=%w", start, end, err)
+		}
+
+		for i, d := range batch {
+			cp := *d
+			cp.Score = normalize(scores[i])
+			scored[start+i] = &cp
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+
+	if r.cfg.TopN > 0 && len(scored) > r.cfg.TopN {
+		scored = scored[:r.cfg.TopN]
+	}
+	return scored, nil
+}
+
+func (r *crossEncoderReranker) scoreBatch(ctx context.Context, query string, docs []*rerank.Document) ([]float64, error) {
+	contents := make([]string, len(docs))
+	for i, d := range docs {
+		contents[i] = d.Content
+	}
+	body, err := json.Marshal(scoreRequest{Query: query, Documents: contents})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request failed, err=%w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= r.cfg.MaxRetries; attempt++ {
+		scores, err := r.doRequest(ctx, body, len(docs))
+		if err == nil {
+			return scores, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (r *crossEncoderReranker) doRequest(ctx context.Context, body []byte, want int) ([]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.BaseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request failed, err=%w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.cfg.APIKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request failed, err=%w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response failed, err=%w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status=%d, body=%s", resp.StatusCode, respBody)
+	}
+
+	var sr scoreResponse
+	if err := json.Unmarshal(respBody, &sr); err != nil {
+		return nil, fmt.Errorf("unmarshal response failed, err=%w", err)
+	}
+	if len(sr.Scores) != want {
+		return nil, fmt.Errorf("expected %d scores, got %d", want, len(sr.Scores))
+	}
+	return sr.Scores, nil
+}
+
+// normalize clamps a cross-encoder score into [0, 1]. Some services (e.g.
+// raw logits) can return values outside that range; downstream callers that
+// compare scores across rerankers assume a common [0, 1] scale.
+func normalize(score float64) float64 {
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package llm implements rerank.Reranker by asking a chat model to score how
+// relevant each candidate document is to the query, on the existing
+// chatmodel.Factory/ModelFactory path InitService already uses for M2Q and
+// NL2SQL, rather than standing up a separate model client.
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/rerank"
+)
+
+// defaultTopN caps how many documents get fused back in when the caller
+// doesn't configure one, matching rrf.NewRRFReranker's "0 means return all"
+// convention for an unset topN.
+const defaultTopN = 0
+
+// scorePromptTemplate asks the model to return a JSON array of floats in
+// [0, 1], one per document, in the same order the documents were given. A
+// strict, parseable shape is easier to get right from a chat model than
+// asking it to reorder/re-list the documents itself.
+const scorePromptTemplate = `You are a relevance scoring function. Given a search query and a numbered list of candidate documents, return ONLY a JSON array of numbers between 0 and 1, one per document in the same order, where 1 means highly relevant and 0 means not relevant at all. Do not include any explanation.
+
+Query: %s
+
+Documents:
+%s`
+
+type Config struct {
+	ChatModel model.BaseChatModel // required
+	TopN      int                 // 0 means return all, reordered
+}
+
+type llmReranker struct {
+	cm   model.BaseChatModel
+	topN int
+}
+
+// NewLLMReranker wraps an already-constructed chat model (typically built via
+// the same internal.GetBuiltinChatModel helper InitService uses for M2Q /
+// NL2SQL) as a rerank.Reranker.
+func NewLLMReranker(cfg *Config) (rerank.Reranker, error) {
+	if cfg.ChatModel == nil {
+		return nil, fmt.Errorf("[NewLLMReranker] chat model not provided")
+	}
+	topN := cfg.TopN
+	if topN < 0 {
+		topN = defaultTopN
+	}
+	return &llmReranker{cm: cfg.ChatModel, topN: topN}, nil
+}
+
+func (r *llmReranker) Rerank(ctx context.Context, query string, docs []*rerank.Document) ([]*rerank.Document, error) {
+	if len(docs) == 0 {
+		return docs, nil
+	}
+
+	var sb strings.Builder
+	for i, d := range docs {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, d.Content)
+	}
+
+	msg, err := r.cm.Generate(ctx, []*schema.Message{
+		schema.UserMessage(fmt.Sprintf(scorePromptTemplate, query, sb.String())),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[Rerank] chat model generate failed, err=%w", err)
+	}
+
+	scores, err := parseScores(msg.Content, len(docs))
+	if err != nil {
+		return nil, fmt.Errorf("[Rerank] parse model scores failed, err=%w", err)
+	}
+
+	scored := make([]*rerank.Document, len(docs))
+	for i, d := range docs {
+		cp := *d
+		cp.Score = scores[i]
+		scored[i] = &cp
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+
+	if r.topN > 0 && len(scored) > r.topN {
+		scored = scored[:r.topN]
+	}
+	return scored, nil
+}
+
+// parseScores extracts the JSON float array from the model's reply, tolerant
+// of a model wrapping it in a code fence or a leading/trailing sentence.
+func parseScores(content string, want int) ([]float64, error) {
+	start := strings.IndexByte(content, '[')
+	end := strings.LastIndexByte(content, ']')
+	if start == -1 || end == -1 || end < start {
+		return nil, fmt.Errorf("no JSON array found in model output: %q", content)
+	}
+
+	var scores []float64
+	if err := json.Unmarshal([]byte(content[start:end+1]), &scores); err != nil {
+		return nil, fmt.Errorf("invalid JSON array in model output: %w", err)
+	}
+	if len(scores) != want {
+		return nil, fmt.Errorf("expected %d scores, got %d", want, len(scores))
+	}
+	return scores, nil
+}
+
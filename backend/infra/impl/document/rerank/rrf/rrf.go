@@ -0,0 +1,84 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package rrf implements rerank.Reranker with the Reciprocal Rank Fusion
+// term 1/(k+rank), taken from each document's position in the incoming list.
+// Unlike a scoring reranker it needs no model call, so it's the zero-cost
+// default every profile falls back to.
+package rrf
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/rerank"
+)
+
+// defaultRRFK is RRF's smoothing constant k in 1/(k+rank); 60 is the value
+// the original RRF paper and most hybrid search implementations default to.
+const defaultRRFK = 60
+
+type rrfReranker struct {
+	topN int
+	k    int
+}
+
+// NewRRFReranker builds an RRF reranker that returns at most topN documents
+// (topN <= 0 means "return all, reordered").
+func NewRRFReranker(topN int) rerank.Reranker {
+	return &rrfReranker{topN: topN, k: defaultRRFK}
+}
+
+// New adapts NewRRFReranker to rerank.Factory so it can be registered in the
+// reranker registry and selected by profile config (type: rrf).
+func New(cfg rerank.Config) (rerank.Reranker, error) {
+	topN := 0
+	if v, ok := cfg.Params["top_n"]; ok {
+		n, err := strconv.Atoi(v)
+		if err == nil {
+			topN = n
+		}
+	}
+	return NewRRFReranker(topN), nil
+}
+
+func init() {
+	rerank.Register("rrf", rerank.FactoryFunc(New))
+}
+
+// Rerank scores each doc as 1 / (k + rank), where rank is its 1-based
+// position in docs, then sorts by that score, descending, so the result keeps
+// the incoming order (cut to topN). Docs are not grouped by ID or by source:
+// a doc that appears more than once keeps one entry per occurrence, and no
+// scores are summed.
+func (r *rrfReranker) Rerank(ctx context.Context, query string, docs []*rerank.Document) ([]*rerank.Document, error) {
+	scored := make([]*rerank.Document, len(docs))
+	for i, d := range docs {
+		cp := *d
+		cp.Score = 1.0 / float64(r.k+i+1)
+		scored[i] = &cp
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+
+	if r.topN > 0 && len(scored) > r.topN {
+		scored = scored[:r.topN]
+	}
+	return scored, nil
+}
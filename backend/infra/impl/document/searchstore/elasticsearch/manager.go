@@ -0,0 +1,145 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package elasticsearch implements searchstore.Manager on top of
+// Elasticsearch / OpenSearch 8, using a dense_vector field for kNN retrieval
+// and a text field for BM25 matching, fused at query time with RRF.
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+	"github.com/coze-dev/coze-studio/backend/infra/contract/embedding"
+)
+
+// defaultTextAnalyzer is used for the content field when the caller does not
+// configure one. "standard" matches Elasticsearch's own default, so indices
+// created without an explicit analyzer behave the same as before this field
+// existed.
+const defaultTextAnalyzer = "standard"
+
+type ManagerConfig struct {
+	Client    *elasticsearch.Client // required
+	Embedding embedding.Embedder    // required
+
+	// Analyzer configures the analyzer used for the content text field.
+	// Defaults to defaultTextAnalyzer when empty.
+	Analyzer string
+}
+
+func NewManager(config *ManagerConfig) (searchstore.Manager, error) {
+	if config.Client == nil {
+		return nil, fmt.Errorf("[NewManager] elasticsearch client not provided")
+	}
+	if config.Embedding == nil {
+		return nil, fmt.Errorf("[NewManager] elasticsearch embedder not provided")
+	}
+	if config.Analyzer == "" {
+		config.Analyzer = defaultTextAnalyzer
+	}
+
+	return &esManager{config: config}, nil
+}
+
+type esManager struct {
+	config *ManagerConfig
+}
+
+func (m *esManager) Create(ctx context.Context, req *searchstore.CreateRequest) error {
+	dims := m.config.Embedding.Dimensions()
+
+	mapping := map[string]any{
+		"mappings": map[string]any{
+			"properties": map[string]any{
+				"content": map[string]any{
+					"type":     "text",
+					"analyzer": m.config.Analyzer,
+				},
+				"embedding": map[string]any{
+					"type":       "dense_vector",
+					"dims":       dims,
+					"index":      true,
+					"similarity": "cosine",
+				},
+				"metadata": map[string]any{
+					"type": "object",
+				},
+			},
+		},
+	}
+
+	body, err := json.Marshal(mapping)
+	if err != nil {
+		return fmt.Errorf("[Create] marshal mapping failed, %w", err)
+	}
+
+	res, err := esapi.IndicesCreateRequest{
+		Index: indexName(req.CollectionName),
+		Body:  bytes.NewReader(body),
+	}.Do(ctx, m.config.Client)
+	if err != nil {
+		return fmt.Errorf("[Create] create index failed, %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("[Create] create index failed, status=%s", res.Status())
+	}
+
+	return nil
+}
+
+func (m *esManager) Drop(ctx context.Context, req *searchstore.DropRequest) error {
+	res, err := esapi.IndicesDeleteRequest{
+		Index: []string{indexName(req.CollectionName)},
+	}.Do(ctx, m.config.Client)
+	if err != nil {
+		return fmt.Errorf("[Drop] delete index failed, %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() && res.StatusCode != 404 {
+		return fmt.Errorf("[Drop] delete index failed, status=%s", res.Status())
+	}
+
+	return nil
+}
+
+func (m *esManager) GetType() searchstore.SearchStoreType {
+	return searchstore.TypeVectorStore
+}
+
+func (m *esManager) GetSearchStore(ctx context.Context, collectionName string) (searchstore.SearchStore, error) {
+	return &esSearchStore{
+		config:         m.config,
+		collectionName: collectionName,
+	}, nil
+}
+
+func (m *esManager) GetEmbedding() embedding.Embedder {
+	return m.config.Embedding
+}
+
+func indexName(collectionName string) string {
+	return "coze_ss_" + collectionName
+}
@@ -0,0 +1,293 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+	"github.com/coze-dev/coze-studio/backend/pkg/lang/ptr"
+)
+
+const (
+	topK = 10
+
+	// defaultRRFK / defaultRRFCandidateCap mirror the constants used by the
+	// OceanBase backend's hybrid search, so the two backends fuse knn and
+	// match results identically regardless of which one a deployment picks.
+	defaultRRFK             = 60
+	defaultRRFCandidateCap  = 200
+	defaultKNNNumCandidates = 500
+)
+
+type esSearchStore struct {
+	config         *ManagerConfig
+	collectionName string
+}
+
+type esHit struct {
+	ID      string         `json:"_id"`
+	Source  map[string]any `json:"_source"`
+	Content string         `json:"-"`
+}
+
+func (s *esSearchStore) Retrieve(ctx context.Context, query string, opts ...retriever.Option) ([]*schema.Document, error) {
+	options := retriever.GetCommonOptions(&retriever.Options{TopK: ptr.Of(topK)}, opts...)
+	implSpecOptions := retriever.GetImplSpecificOptions(&searchstore.RetrieverOptions{}, opts...)
+
+	k := defaultRRFK
+	cap_ := defaultRRFCandidateCap
+	if implSpecOptions.RRFK != nil {
+		k = *implSpecOptions.RRFK
+	}
+	if implSpecOptions.RRFCandidateCap != nil {
+		cap_ = *implSpecOptions.RRFCandidateCap
+	}
+
+	vectorRanked, err := s.knnSearch(ctx, query, cap_)
+	if err != nil {
+		return nil, fmt.Errorf("[Retrieve] knn search failed, %w", err)
+	}
+
+	textRanked, err := s.matchSearch(ctx, query, cap_)
+	if err != nil {
+		return nil, fmt.Errorf("[Retrieve] match search failed, %w", err)
+	}
+
+	return fuseRRF(vectorRanked, textRanked, k, *options.TopK), nil
+}
+
+// knnSearch runs the dense_vector kNN leg alone and returns it ranked by
+// similarity, best first.
+func (s *esSearchStore) knnSearch(ctx context.Context, query string, size int) ([]esHit, error) {
+	emb, err := s.config.Embedding.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("[knnSearch] embed failed, %w", err)
+	}
+	if len(emb) != 1 {
+		return nil, fmt.Errorf("[knnSearch] unexpected embedding size, expected=1, got=%d", len(emb))
+	}
+
+	body := map[string]any{
+		"knn": map[string]any{
+			"field":          "embedding",
+			"query_vector":   emb[0],
+			"k":              size,
+			"num_candidates": defaultKNNNumCandidates,
+		},
+		"size": size,
+	}
+
+	return s.doSearch(ctx, body)
+}
+
+// matchSearch runs the BM25 text leg alone and returns it ranked by
+// relevance, best first.
+func (s *esSearchStore) matchSearch(ctx context.Context, query string, size int) ([]esHit, error) {
+	body := map[string]any{
+		"query": map[string]any{
+			"match": map[string]any{
+				"content": query,
+			},
+		},
+		"size": size,
+	}
+
+	return s.doSearch(ctx, body)
+}
+
+func (s *esSearchStore) doSearch(ctx context.Context, body map[string]any) ([]esHit, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal query failed, %w", err)
+	}
+
+	res, err := esapi.SearchRequest{
+		Index: []string{indexName(s.collectionName)},
+		Body:  bytes.NewReader(payload),
+	}.Do(ctx, s.config.Client)
+	if err != nil {
+		return nil, fmt.Errorf("search request failed, %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("search request failed, status=%s", res.Status())
+	}
+
+	var parsed struct {
+		Hits struct {
+			Hits []esHit `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("decode response failed, %w", err)
+	}
+
+	return parsed.Hits.Hits, nil
+}
+
+// fuseRRF merges two ranked hit lists by Reciprocal Rank Fusion, the same
+// strategy oceanbaseSearchStore.hybridSearch uses, and returns the top n
+// fused documents.
+func fuseRRF(vector, text []esHit, k int, n int) []*schema.Document {
+	type fused struct {
+		doc   *schema.Document
+		score float64
+	}
+
+	byID := make(map[string]*fused, len(vector)+len(text))
+	var order []string
+
+	addRanked := func(hits []esHit) {
+		for i, h := range hits {
+			f, ok := byID[h.ID]
+			if !ok {
+				f = &fused{doc: toDocument(h)}
+				byID[h.ID] = f
+				order = append(order, h.ID)
+			}
+			f.score += 1.0 / float64(k+i+1)
+		}
+	}
+	addRanked(vector)
+	addRanked(text)
+
+	docs := make([]*schema.Document, 0, len(order))
+	for _, id := range order {
+		f := byID[id]
+		f.doc.WithScore(f.score)
+		docs = append(docs, f.doc)
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Score() > docs[j].Score()
+	})
+
+	if len(docs) > n {
+		docs = docs[:n]
+	}
+	return docs
+}
+
+func toDocument(h esHit) *schema.Document {
+	content, _ := h.Source["content"].(string)
+	metadata, _ := h.Source["metadata"].(map[string]any)
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
+	return &schema.Document{
+		ID:       h.ID,
+		Content:  content,
+		MetaData: metadata,
+	}
+}
+
+func (s *esSearchStore) Store(ctx context.Context, docs []*schema.Document, opts ...indexer.Option) (ids []string, err error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	implSpecOptions := indexer.GetImplSpecificOptions(&searchstore.IndexerOptions{}, opts...)
+
+	defer func() {
+		if err != nil {
+			if implSpecOptions.ProgressBar != nil {
+				_ = implSpecOptions.ProgressBar.ReportError(err)
+			}
+		}
+	}()
+
+	ids = make([]string, 0, len(docs))
+	for _, doc := range docs {
+		emb, err := s.config.Embedding.EmbedStrings(ctx, []string{doc.Content})
+		if err != nil {
+			return nil, fmt.Errorf("[Store] embed failed, %w", err)
+		}
+		if len(emb) != 1 {
+			return nil, fmt.Errorf("[Store] unexpected embedding size, expected=1, got=%d", len(emb))
+		}
+
+		body := map[string]any{
+			"content":   doc.Content,
+			"embedding": emb[0],
+			"metadata":  doc.MetaData,
+		}
+
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("[Store] marshal document failed, %w", err)
+		}
+
+		res, err := esapi.IndexRequest{
+			Index:      indexName(s.collectionName),
+			DocumentID: doc.ID,
+			Body:       bytes.NewReader(payload),
+		}.Do(ctx, s.config.Client)
+		if err != nil {
+			return nil, fmt.Errorf("[Store] index document failed, %w", err)
+		}
+		res.Body.Close()
+
+		if res.IsError() {
+			return nil, fmt.Errorf("[Store] index document failed, status=%s", res.Status())
+		}
+
+		ids = append(ids, doc.ID)
+
+		if implSpecOptions.ProgressBar != nil {
+			if err = implSpecOptions.ProgressBar.AddN(1); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+func (s *esSearchStore) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	for _, id := range ids {
+		res, err := esapi.DeleteRequest{
+			Index:      indexName(s.collectionName),
+			DocumentID: id,
+		}.Do(ctx, s.config.Client)
+		if err != nil {
+			return fmt.Errorf("[Delete] delete document failed, %w", err)
+		}
+		res.Body.Close()
+
+		if res.IsError() && res.StatusCode != 404 {
+			return fmt.Errorf("[Delete] delete document failed, status=%s", res.Status())
+		}
+	}
+
+	return nil
+}
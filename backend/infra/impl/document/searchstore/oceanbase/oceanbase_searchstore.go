@@ -19,6 +19,7 @@ package oceanbase
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/cloudwego/eino/components/indexer"
@@ -28,11 +29,22 @@ import (
 
 	"github.com/coze-dev/coze-studio/backend/infra/contract/document"
 	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+	"github.com/coze-dev/coze-studio/backend/infra/impl/document/searchstore/sqlfilter"
 	"github.com/coze-dev/coze-studio/backend/pkg/lang/ptr"
 )
 
 const (
 	topK = 10
+
+	// defaultRRFK is the RRF smoothing constant used when the caller does not
+	// override it via searchstore.RetrieverOptions. 60 is the value used by the
+	// original Cormack/Clarke/Buettcher paper and works well without tuning.
+	defaultRRFK = 60
+
+	// defaultRRFCandidateCap bounds how many rows each leg (vector / fulltext)
+	// pulls before fusion, so the two ranked lists have enough overlap to fuse
+	// against even when TopK is small.
+	defaultRRFCandidateCap = 200
 )
 
 type oceanbaseSearchStore struct {
@@ -78,78 +90,155 @@ func (o *oceanbaseSearchStore) determineSearchType(query string, implSpecOptions
 	return "hybrid"
 }
 
+// rankedDoc is one row of a single-source ranked list, as produced by
+// rankedVectorSearch / rankedFulltextSearch ahead of RRF fusion.
+type rankedDoc struct {
+	doc  *schema.Document
+	rank int // 1-based rank within its source list
+}
+
+// hybridSearch fuses the vector leg and the fulltext leg with Reciprocal Rank
+// Fusion instead of blending their raw scores: the two legs live on
+// incomparable scales (cosine distance vs. MATCH...AGAINST relevance), so a
+// weighted sum of scores is fragile, while a fusion of ranks is not.
+// score(doc) = sum over lists containing doc of weight_i / (k + rank_i(doc))
 func (o *oceanbaseSearchStore) hybridSearch(ctx context.Context, query string, options *retriever.Options, implSpecOptions *searchstore.RetrieverOptions) ([]*schema.Document, error) {
-	// 生成查询向量
+	k := defaultRRFK
+	cap_ := defaultRRFCandidateCap
+	vectorWeight, fulltextWeight := 1.0, 1.0
+	if implSpecOptions != nil {
+		if implSpecOptions.RRFK != nil {
+			k = *implSpecOptions.RRFK
+		}
+		if implSpecOptions.RRFCandidateCap != nil {
+			cap_ = *implSpecOptions.RRFCandidateCap
+		}
+		if implSpecOptions.RRFSourceWeights != nil {
+			if w, ok := implSpecOptions.RRFSourceWeights["vector"]; ok {
+				vectorWeight = w
+			}
+			if w, ok := implSpecOptions.RRFSourceWeights["fulltext"]; ok {
+				fulltextWeight = w
+			}
+		}
+	}
+
+	candidateTopK := options.TopK
+	if candidateTopK == nil || *candidateTopK < cap_ {
+		candidateTopK = &cap_
+	}
+
+	vectorRanked, err := o.rankedVectorSearch(ctx, query, candidateTopK, implSpecOptions)
+	if err != nil {
+		return nil, fmt.Errorf("[hybridSearch] vector leg failed, %w", err)
+	}
+
+	fulltextRanked, err := o.rankedFulltextSearch(ctx, query, candidateTopK, implSpecOptions)
+	if err != nil {
+		return nil, fmt.Errorf("[hybridSearch] fulltext leg failed, %w", err)
+	}
+
+	return fuseRRF(vectorRanked, fulltextRanked, k, vectorWeight, fulltextWeight, options.TopK), nil
+}
+
+// fuseRRF merges the vector and fulltext ranked lists by Reciprocal Rank
+// Fusion. It's split out of hybridSearch so the fusion math can be tested
+// against synthetic rankedDoc lists without a live OceanBase connection -
+// in particular, so it's easy to confirm fusion degrades gracefully to a
+// single-leg ranking when the other leg returns nothing.
+func fuseRRF(vectorRanked, fulltextRanked []rankedDoc, k int, vectorWeight, fulltextWeight float64, topK *int) []*schema.Document {
+	type fused struct {
+		doc   *schema.Document
+		score float64
+	}
+
+	byID := make(map[string]*fused, len(vectorRanked)+len(fulltextRanked))
+	order := make([]string, 0, len(vectorRanked)+len(fulltextRanked))
+
+	addRanked := func(ranked []rankedDoc, weight float64) {
+		for _, r := range ranked {
+			f, ok := byID[r.doc.ID]
+			if !ok {
+				f = &fused{doc: r.doc}
+				byID[r.doc.ID] = f
+				order = append(order, r.doc.ID)
+			}
+			f.score += weight / float64(k+r.rank)
+		}
+	}
+	addRanked(vectorRanked, vectorWeight)
+	addRanked(fulltextRanked, fulltextWeight)
+
+	docs := make([]*schema.Document, 0, len(order))
+	for _, id := range order {
+		f := byID[id]
+		f.doc.WithScore(f.score)
+		docs = append(docs, f.doc)
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Score() > docs[j].Score()
+	})
+
+	if topK != nil && len(docs) > *topK {
+		docs = docs[:*topK]
+	}
+
+	return docs
+}
+
+// rankedVectorSearch runs the vector leg alone and returns it as a 1-based
+// ranked list, so it can be fused by rank rather than by raw distance.
+func (o *oceanbaseSearchStore) rankedVectorSearch(ctx context.Context, query string, limit *int, implSpecOptions *searchstore.RetrieverOptions) ([]rankedDoc, error) {
 	emb, err := o.config.Embedding.EmbedStrings(ctx, []string{query})
 	if err != nil {
-		return nil, fmt.Errorf("[hybridSearch] embed failed, %w", err)
+		return nil, fmt.Errorf("[rankedVectorSearch] embed failed, %w", err)
 	}
 	if len(emb) != 1 {
-		return nil, fmt.Errorf("[hybridSearch] unexpected embedding size, expected=1, got=%d", len(emb))
+		return nil, fmt.Errorf("[rankedVectorSearch] unexpected embedding size, expected=1, got=%d", len(emb))
+	}
+
+	vectorStr := fmt.Sprintf("[%s]", strings.Trim(strings.ReplaceAll(fmt.Sprintf("%v", emb[0]), " ", ","), "[]"))
+
+	whereClause, whereArgs, err := buildFilterClause(implSpecOptions)
+	if err != nil {
+		return nil, fmt.Errorf("[rankedVectorSearch] %w", err)
 	}
 
-	// 构建混合搜索SQL，结合向量搜索和全文搜索
-	// 使用WITH子句分别进行向量搜索和全文搜索，然后合并结果
-	tableName := o.getVectorTableName()
 	sql := fmt.Sprintf(`
-		WITH vector_results AS (
-			SELECT id, content, creator_id, create_time, update_time,
-				   cosine_distance(embedding, ?) as vector_distance
-			FROM %s
-			WHERE collection_name = ?
-			  AND embedding IS NOT NULL
-			ORDER BY vector_distance
-			LIMIT ?
-		),
-		fulltext_results AS (
-			SELECT id, content, creator_id, create_time, update_time,
-				   MATCH(content) AGAINST(? IN NATURAL LANGUAGE MODE) as relevance
-			FROM %s
-			WHERE collection_name = ?
-			  AND MATCH(content) AGAINST(? IN NATURAL LANGUAGE MODE)
-			ORDER BY relevance DESC
-			LIMIT ?
-		),
-		combined_results AS (
-			SELECT 
-				COALESCE(v.id, f.id) as id,
-				COALESCE(v.content, f.content) as content,
-				COALESCE(v.creator_id, f.creator_id) as creator_id,
-				COALESCE(v.create_time, f.create_time) as create_time,
-				COALESCE(v.update_time, f.update_time) as update_time,
-				COALESCE(v.vector_distance, 1.0) as vector_distance,
-				COALESCE(f.relevance, 0.0) as relevance,
-				(0.7 * (1.0 - COALESCE(v.vector_distance, 1.0)) + 0.3 * COALESCE(f.relevance, 0.0)) as combined_score
-			FROM vector_results v
-			FULL OUTER JOIN fulltext_results f ON v.id = f.id
-		)
-		SELECT id, content, creator_id, create_time, update_time, combined_score
-		FROM combined_results
-		ORDER BY combined_score DESC
+		SELECT id, content, creator_id, create_time, update_time,
+		       cosine_distance(embedding, ?) as distance
+		FROM %s
+		WHERE collection_name = ?
+		  AND embedding IS NOT NULL
+		  %s
+		ORDER BY distance
 		LIMIT ?
-	`, tableName, tableName)
+	`, o.getVectorTableName(), whereClause)
 
-	// 将向量数据转换为字符串格式
-	vectorStr := fmt.Sprintf("[%s]", strings.Trim(strings.ReplaceAll(fmt.Sprintf("%v", emb[0]), " ", ","), "[]"))
+	args := append([]interface{}{vectorStr, o.collectionName}, whereArgs...)
+	args = append(args, *limit)
 
-	rows, err := o.db.Raw(sql, vectorStr, o.collectionName, options.TopK, query, o.collectionName, query, options.TopK, options.TopK).Rows()
+	rows, err := o.db.Raw(sql, args...).Rows()
 	if err != nil {
-		return nil, fmt.Errorf("[hybridSearch] query failed, %w", err)
+		return nil, fmt.Errorf("[rankedVectorSearch] query failed, %w", err)
 	}
 	defer rows.Close()
 
-	var docs []*schema.Document
+	var ranked []rankedDoc
+	rank := 0
 	for rows.Next() {
 		var id int64
 		var content string
 		var creatorID int64
 		var createTime, updateTime int64
-		var combinedScore float64
+		var distance float64
 
-		if err := rows.Scan(&id, &content, &creatorID, &createTime, &updateTime, &combinedScore); err != nil {
-			return nil, fmt.Errorf("[hybridSearch] scan failed, %w", err)
+		if err := rows.Scan(&id, &content, &creatorID, &createTime, &updateTime, &distance); err != nil {
+			return nil, fmt.Errorf("[rankedVectorSearch] scan failed, %w", err)
 		}
 
+		rank++
 		doc := &schema.Document{
 			ID:      fmt.Sprintf("%d", id),
 			Content: content,
@@ -159,12 +248,68 @@ func (o *oceanbaseSearchStore) hybridSearch(ctx context.Context, query string, o
 				"update_time":                 updateTime,
 			},
 		}
-		doc.WithScore(combinedScore)
+		ranked = append(ranked, rankedDoc{doc: doc, rank: rank})
+	}
 
-		docs = append(docs, doc)
+	return ranked, nil
+}
+
+// rankedFulltextSearch runs the fulltext leg alone and returns it as a
+// 1-based ranked list, so it can be fused by rank rather than by raw MATCH
+// relevance.
+func (o *oceanbaseSearchStore) rankedFulltextSearch(ctx context.Context, query string, limit *int, implSpecOptions *searchstore.RetrieverOptions) ([]rankedDoc, error) {
+	whereClause, whereArgs, err := buildFilterClause(implSpecOptions)
+	if err != nil {
+		return nil, fmt.Errorf("[rankedFulltextSearch] %w", err)
 	}
 
-	return docs, nil
+	sql := fmt.Sprintf(`
+		SELECT id, content, creator_id, create_time, update_time,
+		       MATCH(content) AGAINST(? IN NATURAL LANGUAGE MODE) as relevance
+		FROM %s
+		WHERE collection_name = ?
+		  AND MATCH(content) AGAINST(? IN NATURAL LANGUAGE MODE)
+		  %s
+		ORDER BY relevance DESC
+		LIMIT ?
+	`, o.getVectorTableName(), whereClause)
+
+	args := append([]interface{}{query, o.collectionName, query}, whereArgs...)
+	args = append(args, *limit)
+
+	rows, err := o.db.Raw(sql, args...).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("[rankedFulltextSearch] query failed, %w", err)
+	}
+	defer rows.Close()
+
+	var ranked []rankedDoc
+	rank := 0
+	for rows.Next() {
+		var id int64
+		var content string
+		var creatorID int64
+		var createTime, updateTime int64
+		var relevance float64
+
+		if err := rows.Scan(&id, &content, &creatorID, &createTime, &updateTime, &relevance); err != nil {
+			return nil, fmt.Errorf("[rankedFulltextSearch] scan failed, %w", err)
+		}
+
+		rank++
+		doc := &schema.Document{
+			ID:      fmt.Sprintf("%d", id),
+			Content: content,
+			MetaData: map[string]any{
+				document.MetaDataKeyCreatorID: creatorID,
+				"create_time":                 createTime,
+				"update_time":                 updateTime,
+			},
+		}
+		ranked = append(ranked, rankedDoc{doc: doc, rank: rank})
+	}
+
+	return ranked, nil
 }
 
 func (o *oceanbaseSearchStore) vectorSearch(ctx context.Context, query string, options *retriever.Options, implSpecOptions *searchstore.RetrieverOptions) ([]*schema.Document, error) {
@@ -178,20 +323,28 @@ func (o *oceanbaseSearchStore) vectorSearch(ctx context.Context, query string, o
 	}
 
 	// 构建向量搜索SQL，使用cosine_distance进行相似度计算
+	whereClause, whereArgs, err := buildFilterClause(implSpecOptions)
+	if err != nil {
+		return nil, fmt.Errorf("[vectorSearch] %w", err)
+	}
 	sql := fmt.Sprintf(`
 		SELECT id, content, creator_id, create_time, update_time,
 		       cosine_distance(embedding, ?) as distance
 		FROM %s
 		WHERE collection_name = ?
 		  AND embedding IS NOT NULL
+		  %s
 		ORDER BY distance
 		LIMIT ?
-	`, o.getVectorTableName())
+	`, o.getVectorTableName(), whereClause)
 
 	// 将向量数据转换为字符串格式
 	vectorStr := fmt.Sprintf("[%s]", strings.Trim(strings.ReplaceAll(fmt.Sprintf("%v", emb[0]), " ", ","), "[]"))
 
-	rows, err := o.db.Raw(sql, vectorStr, o.collectionName, options.TopK).Rows()
+	args := append([]interface{}{vectorStr, o.collectionName}, whereArgs...)
+	args = append(args, options.TopK)
+
+	rows, err := o.db.Raw(sql, args...).Rows()
 	if err != nil {
 		return nil, fmt.Errorf("[vectorSearch] query failed, %w", err)
 	}
@@ -228,17 +381,25 @@ func (o *oceanbaseSearchStore) vectorSearch(ctx context.Context, query string, o
 
 func (o *oceanbaseSearchStore) fulltextSearch(ctx context.Context, query string, options *retriever.Options, implSpecOptions *searchstore.RetrieverOptions) ([]*schema.Document, error) {
 	// 构建全文搜索SQL，使用MATCH AGAINST进行全文检索
+	whereClause, whereArgs, err := buildFilterClause(implSpecOptions)
+	if err != nil {
+		return nil, fmt.Errorf("[fulltextSearch] %w", err)
+	}
 	sql := fmt.Sprintf(`
 		SELECT id, content, creator_id, create_time, update_time,
 		       MATCH(content) AGAINST(? IN NATURAL LANGUAGE MODE) as relevance
 		FROM %s
 		WHERE collection_name = ?
 		  AND MATCH(content) AGAINST(? IN NATURAL LANGUAGE MODE)
+		  %s
 		ORDER BY relevance DESC
 		LIMIT ?
-	`, o.getVectorTableName())
+	`, o.getVectorTableName(), whereClause)
+
+	args := append([]interface{}{query, o.collectionName, query}, whereArgs...)
+	args = append(args, options.TopK)
 
-	rows, err := o.db.Raw(sql, query, o.collectionName, query, options.TopK).Rows()
+	rows, err := o.db.Raw(sql, args...).Rows()
 	if err != nil {
 		return nil, fmt.Errorf("[fulltextSearch] query failed, %w", err)
 	}
@@ -352,3 +513,12 @@ func (o *oceanbaseSearchStore) Delete(ctx context.Context, ids []string) error {
 func (o *oceanbaseSearchStore) getVectorTableName() string {
 	return "knowledge_vectors"
 }
+
+// buildFilterClause translates implSpecOptions.Filter into an extra SQL
+// fragment appended after the collection_name predicate.
+func buildFilterClause(implSpecOptions *searchstore.RetrieverOptions) (string, []interface{}, error) {
+	if implSpecOptions == nil || implSpecOptions.Filter == nil {
+		return "", nil, nil
+	}
+	return sqlfilter.Build(implSpecOptions.Filter)
+}
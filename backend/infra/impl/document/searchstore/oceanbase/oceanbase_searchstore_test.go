@@ -0,0 +1,90 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oceanbase
+
+import (
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func rankedDocs(ids ...string) []rankedDoc {
+	out := make([]rankedDoc, 0, len(ids))
+	for i, id := range ids {
+		out = append(out, rankedDoc{doc: &schema.Document{ID: id, Content: id}, rank: i + 1})
+	}
+	return out
+}
+
+func docIDs(docs []*schema.Document) []string {
+	ids := make([]string, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+func TestFuseRRF_VectorOnly(t *testing.T) {
+	vector := rankedDocs("a", "b", "c")
+	docs := fuseRRF(vector, nil, defaultRRFK, 1.0, 1.0, nil)
+
+	got := docIDs(docs)
+	want := []string{"a", "b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected result count, got=%v want=%v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("fusion should fall back to the vector leg's own order when fulltext is empty, got=%v want=%v", got, want)
+		}
+	}
+}
+
+func TestFuseRRF_FulltextOnly(t *testing.T) {
+	fulltext := rankedDocs("x", "y")
+	docs := fuseRRF(nil, fulltext, defaultRRFK, 1.0, 1.0, nil)
+
+	got := docIDs(docs)
+	want := []string{"x", "y"}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected result count, got=%v want=%v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("fusion should fall back to the fulltext leg's own order when vector is empty, got=%v want=%v", got, want)
+		}
+	}
+}
+
+func TestFuseRRF_BothEmpty(t *testing.T) {
+	docs := fuseRRF(nil, nil, defaultRRFK, 1.0, 1.0, nil)
+	if len(docs) != 0 {
+		t.Fatalf("expected no results when both legs are empty, got=%v", docIDs(docs))
+	}
+}
+
+func TestFuseRRF_MergesOverlapAheadOfEitherLegAlone(t *testing.T) {
+	// "b" appears in both legs, so its fused score should beat a doc that
+	// only appears once, even if that doc ranked #1 on its own leg.
+	vector := rankedDocs("a", "b")
+	fulltext := rankedDocs("b", "c")
+
+	docs := fuseRRF(vector, fulltext, defaultRRFK, 1.0, 1.0, nil)
+	if len(docs) == 0 || docs[0].ID != "b" {
+		t.Fatalf("expected doc appearing in both legs to rank first, got=%v", docIDs(docs))
+	}
+}
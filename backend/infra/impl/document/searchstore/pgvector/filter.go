@@ -0,0 +1,211 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pgvector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+)
+
+// indexedColumns mirrors sqlfilter's set for the OceanBase schema: physical
+// columns every row exposes, as opposed to arbitrary keys that live in the
+// JSONB metadata column.
+var indexedColumns = map[string]bool{
+	"creator_id":  true,
+	"create_time": true,
+	"update_time": true,
+}
+
+// fieldNameRe allow-lists the *shape* of a field name before it's
+// interpolated into the ->> operator expression: f.Field comes from
+// caller-supplied filters, not a fixed schema, so there's no enumerated list
+// to check it against. Rejecting anything that isn't a plain identifier is
+// what actually closes the injection hole rather than just relocating it.
+// Mirrors sqlfilter's fieldNameRe.
+var fieldNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// buildFilterClause translates implSpecOptions.Filter into a SQL fragment
+// appended after the WHERE true predicate. It's a Postgres/JSONB analogue of
+// sqlfilter.Build: the two can't share an implementation because pgvector
+// addresses metadata keys with the ->> operator rather than JSON_EXTRACT.
+func buildFilterClause(implSpecOptions *searchstore.RetrieverOptions) (string, []interface{}, error) {
+	if implSpecOptions == nil || implSpecOptions.Filter == nil {
+		return "", nil, nil
+	}
+	clause, args, err := build(implSpecOptions.Filter)
+	if err != nil {
+		return "", nil, err
+	}
+	if clause == "" {
+		return "", nil, nil
+	}
+	return "AND " + clause, args, nil
+}
+
+func build(f *searchstore.Filter) (string, []interface{}, error) {
+	if f == nil {
+		return "", nil, nil
+	}
+
+	var clauses []string
+	var args []interface{}
+
+	if len(f.And) > 0 {
+		sub, subArgs, err := buildComposite(f.And, " AND ")
+		if err != nil {
+			return "", nil, err
+		}
+		if sub != "" {
+			clauses = append(clauses, sub)
+			args = append(args, subArgs...)
+		}
+	}
+
+	if len(f.Or) > 0 {
+		sub, subArgs, err := buildComposite(f.Or, " OR ")
+		if err != nil {
+			return "", nil, err
+		}
+		if sub != "" {
+			clauses = append(clauses, sub)
+			args = append(args, subArgs...)
+		}
+	}
+
+	if f.Not != nil {
+		sub, subArgs, err := build(f.Not)
+		if err != nil {
+			return "", nil, err
+		}
+		if sub != "" {
+			clauses = append(clauses, "NOT ("+sub+")")
+			args = append(args, subArgs...)
+		}
+	}
+
+	if f.Field != "" {
+		sub, subArgs, err := buildPredicate(f)
+		if err != nil {
+			return "", nil, err
+		}
+		if sub != "" {
+			clauses = append(clauses, sub)
+			args = append(args, subArgs...)
+		}
+	}
+
+	if len(clauses) == 0 {
+		return "", nil, nil
+	}
+	if len(clauses) == 1 {
+		return clauses[0], args, nil
+	}
+	return "(" + strings.Join(clauses, " AND ") + ")", args, nil
+}
+
+func buildComposite(filters []*searchstore.Filter, sep string) (string, []interface{}, error) {
+	var clauses []string
+	var args []interface{}
+	for _, sub := range filters {
+		clause, subArgs, err := build(sub)
+		if err != nil {
+			return "", nil, err
+		}
+		if clause == "" {
+			continue
+		}
+		clauses = append(clauses, clause)
+		args = append(args, subArgs...)
+	}
+	if len(clauses) == 0 {
+		return "", nil, nil
+	}
+	return "(" + strings.Join(clauses, sep) + ")", args, nil
+}
+
+func buildPredicate(f *searchstore.Filter) (string, []interface{}, error) {
+	column, err := resolveColumn(f.Field)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if f.IsNull != nil {
+		if *f.IsNull {
+			return fmt.Sprintf("%s IS NULL", column), nil, nil
+		}
+		return fmt.Sprintf("%s IS NOT NULL", column), nil, nil
+	}
+
+	var clauses []string
+	var args []interface{}
+
+	if f.Eq != nil {
+		clauses = append(clauses, fmt.Sprintf("%s = ?", column))
+		args = append(args, f.Eq)
+	}
+	if len(f.In) > 0 {
+		placeholders := make([]string, len(f.In))
+		for i, v := range f.In {
+			placeholders[i] = "?"
+			args = append(args, v)
+		}
+		clauses = append(clauses, fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ",")))
+	}
+	if f.Gt != nil {
+		clauses = append(clauses, fmt.Sprintf("%s > ?", column))
+		args = append(args, f.Gt)
+	}
+	if f.Gte != nil {
+		clauses = append(clauses, fmt.Sprintf("%s >= ?", column))
+		args = append(args, f.Gte)
+	}
+	if f.Lt != nil {
+		clauses = append(clauses, fmt.Sprintf("%s < ?", column))
+		args = append(args, f.Lt)
+	}
+	if f.Lte != nil {
+		clauses = append(clauses, fmt.Sprintf("%s <= ?", column))
+		args = append(args, f.Lte)
+	}
+
+	if len(clauses) == 0 {
+		return "", nil, nil
+	}
+	if len(clauses) == 1 {
+		return clauses[0], args, nil
+	}
+	return "(" + strings.Join(clauses, " AND ") + ")", args, nil
+}
+
+// resolveColumn maps a filter field name to the SQL expression that reads
+// it: indexed columns are addressed directly, everything else is assumed to
+// be a key inside the JSONB metadata column, read via ->> and cast back to
+// text so it compares against the filter's string-typed args. Rejects any
+// field that isn't a plain identifier before interpolating it, since it's
+// otherwise spliced straight into the quoted ->> expression.
+func resolveColumn(field string) (string, error) {
+	if indexedColumns[field] {
+		return field, nil
+	}
+	if !fieldNameRe.MatchString(field) {
+		return "", fmt.Errorf("invalid filter field name %q", field)
+	}
+	return fmt.Sprintf("(metadata ->> '%s')", field), nil
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package pgvector implements searchstore.Manager on top of Postgres with the
+// pgvector extension: one physical table per collection holding the
+// embedding (vector column), the content (plus a generated tsvector column
+// for BM25-ish matching) and a JSONB metadata column, fused at query time
+// with the same RRF strategy the OceanBase and Elasticsearch backends use.
+package pgvector
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+	"github.com/coze-dev/coze-studio/backend/infra/contract/embedding"
+)
+
+// IndexType selects the pgvector ANN index built on the embedding column.
+type IndexType string
+
+const (
+	IndexTypeHNSW             IndexType = "hnsw"
+	IndexTypeIVFFlat          IndexType = "ivfflat"
+	defaultIndexType                    = IndexTypeHNSW
+	defaultIVFLists                     = 100
+	defaultHNSWM                        = 16
+	defaultHNSWEfConstruction           = 64
+)
+
+type ManagerConfig struct {
+	DB        *gorm.DB           // required
+	Embedding embedding.Embedder // required
+
+	// IndexType selects HNSW (default) or IVFFlat for the ANN index on the
+	// embedding column. HNSW needs no training data and is the safer default
+	// for knowledge bases that start empty; IVFFlat is cheaper to build once
+	// a collection already holds enough rows to pick centroids from.
+	IndexType IndexType
+
+	// IVFLists / HNSWM / HNSWEfConstruction tune the chosen index type.
+	// Zero means use the package defaults.
+	IVFLists           int
+	HNSWM              int
+	HNSWEfConstruction int
+}
+
+func NewManager(config *ManagerConfig) (searchstore.Manager, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("[NewManager] pgvector db not provided")
+	}
+	if config.Embedding == nil {
+		return nil, fmt.Errorf("[NewManager] pgvector embedder not provided")
+	}
+	if config.IndexType == "" {
+		config.IndexType = defaultIndexType
+	}
+	if config.IVFLists == 0 {
+		config.IVFLists = defaultIVFLists
+	}
+	if config.HNSWM == 0 {
+		config.HNSWM = defaultHNSWM
+	}
+	if config.HNSWEfConstruction == 0 {
+		config.HNSWEfConstruction = defaultHNSWEfConstruction
+	}
+
+	return &pgvectorManager{config: config}, nil
+}
+
+type pgvectorManager struct {
+	config *ManagerConfig
+}
+
+// Create provisions the table, tsvector/GIN index and ANN index for
+// collectionName the first time it is used, and validates that an
+// already-existing table's embedding column matches the configured
+// embedder's dimensionality so a profile change can't silently corrupt
+// retrieval.
+func (m *pgvectorManager) Create(ctx context.Context, req *searchstore.CreateRequest) error {
+	table := tableName(req.CollectionName)
+	dims := m.config.Embedding.Dimensions()
+
+	var exists bool
+	if err := m.config.DB.WithContext(ctx).
+		Raw("SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = ?)", table).
+		Scan(&exists).Error; err != nil {
+		return fmt.Errorf("[Create] check table existence failed, %w", err)
+	}
+
+	if exists {
+		return m.validateDimensions(ctx, table, dims)
+	}
+
+	ddl := fmt.Sprintf(`
+		CREATE TABLE %s (
+			id           text PRIMARY KEY,
+			content      text NOT NULL,
+			embedding    vector(%d) NOT NULL,
+			content_tsv  tsvector GENERATED ALWAYS AS (to_tsvector('english', content)) STORED,
+			metadata     jsonb NOT NULL DEFAULT '{}',
+			creator_id   bigint NOT NULL DEFAULT 0,
+			create_time  bigint NOT NULL DEFAULT extract(epoch from now()),
+			update_time  bigint NOT NULL DEFAULT extract(epoch from now())
+		)
+	`, table, dims)
+	if err := m.config.DB.WithContext(ctx).Exec(ddl).Error; err != nil {
+		return fmt.Errorf("[Create] create table failed, %w", err)
+	}
+
+	if err := m.config.DB.WithContext(ctx).Exec(
+		fmt.Sprintf(`CREATE INDEX %s ON %s USING gin (content_tsv)`, table+"_tsv_idx", table)).Error; err != nil {
+		return fmt.Errorf("[Create] create tsvector index failed, %w", err)
+	}
+
+	annDDL := m.annIndexDDL(table)
+	if err := m.config.DB.WithContext(ctx).Exec(annDDL).Error; err != nil {
+		return fmt.Errorf("[Create] create ann index failed, %w", err)
+	}
+
+	return nil
+}
+
+func (m *pgvectorManager) annIndexDDL(table string) string {
+	switch m.config.IndexType {
+	case IndexTypeIVFFlat:
+		return fmt.Sprintf(`CREATE INDEX %s ON %s USING ivfflat (embedding vector_cosine_ops) WITH (lists = %d)`,
+			table+"_ann_idx", table, m.config.IVFLists)
+	default:
+		return fmt.Sprintf(`CREATE INDEX %s ON %s USING hnsw (embedding vector_cosine_ops) WITH (m = %d, ef_construction = %d)`,
+			table+"_ann_idx", table, m.config.HNSWM, m.config.HNSWEfConstruction)
+	}
+}
+
+// validateDimensions guards against a knowledge base being re-pointed at an
+// embedder with a different dimensionality than the table it was created
+// with, which would otherwise fail opaquely on the first insert.
+func (m *pgvectorManager) validateDimensions(ctx context.Context, table string, wantDims int) error {
+	var gotDims int
+	err := m.config.DB.WithContext(ctx).Raw(`
+		SELECT atttypmod FROM pg_attribute
+		WHERE attrelid = ?::regclass AND attname = 'embedding'
+	`, table).Scan(&gotDims).Error
+	if err != nil {
+		return fmt.Errorf("[Create] read existing embedding dimensions failed, %w", err)
+	}
+	if gotDims != wantDims {
+		return fmt.Errorf("[Create] embedding dimension mismatch for table %s, table=%d configured=%d", table, gotDims, wantDims)
+	}
+	return nil
+}
+
+func (m *pgvectorManager) Drop(ctx context.Context, req *searchstore.DropRequest) error {
+	table := tableName(req.CollectionName)
+	if err := m.config.DB.WithContext(ctx).Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", table)).Error; err != nil {
+		return fmt.Errorf("[Drop] drop table failed, %w", err)
+	}
+	return nil
+}
+
+func (m *pgvectorManager) GetType() searchstore.SearchStoreType {
+	return searchstore.TypeVectorStore
+}
+
+func (m *pgvectorManager) GetSearchStore(ctx context.Context, collectionName string) (searchstore.SearchStore, error) {
+	return &pgvectorSearchStore{
+		config:         m.config,
+		collectionName: collectionName,
+		table:          tableName(collectionName),
+		db:             m.config.DB,
+	}, nil
+}
+
+func (m *pgvectorManager) GetEmbedding() embedding.Embedder {
+	return m.config.Embedding
+}
+
+// tableName maps a collection name to its physical table name. Collection
+// names come from the knowledge domain and may contain characters Postgres
+// won't accept unquoted in an identifier, so they're hex-encoded rather than
+// interpolated as-is.
+func tableName(collectionName string) string {
+	return fmt.Sprintf("ss_pgv_%x", []byte(collectionName))
+}
@@ -0,0 +1,180 @@
+//go:build integration
+
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pgvector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+)
+
+// fakeEmbedder is a deterministic stand-in for embedding.Embedder: each
+// string's vector is derived from its own byte sum, so Retrieve can be
+// exercised without a real embedding provider.
+type fakeEmbedder struct{ dims int }
+
+func (f *fakeEmbedder) Dimensions() int { return f.dims }
+
+func (f *fakeEmbedder) EmbedStrings(ctx context.Context, texts []string) ([][]float64, error) {
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		vec := make([]float64, f.dims)
+		var sum float64
+		for _, b := range []byte(text) {
+			sum += float64(b)
+		}
+		vec[0] = sum
+		out[i] = vec
+	}
+	return out, nil
+}
+
+// startPostgres brings up a Postgres 16 + pgvector container via
+// testcontainers-go and returns a *gorm.DB connected to it.
+func startPostgres(t *testing.T) *gorm.DB {
+	t.Helper()
+	ctx := context.Background()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "pgvector/pgvector:pg16",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_USER":     "postgres",
+				"POSTGRES_PASSWORD": "postgres",
+				"POSTGRES_DB":       "opencoze_test",
+			},
+			WaitingFor: wait.ForListeningPort("5432/tcp").WithStartupTimeout(60 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("start postgres container failed: %v", err)
+	}
+	t.Cleanup(func() { _ = container.Terminate(ctx) })
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("get container host failed: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "5432/tcp")
+	if err != nil {
+		t.Fatalf("get mapped port failed: %v", err)
+	}
+
+	dsn := fmt.Sprintf("host=%s user=postgres password=postgres dbname=opencoze_test port=%s sslmode=disable", host, port.Port())
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("connect to postgres failed: %v", err)
+	}
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+		t.Fatalf("create vector extension failed: %v", err)
+	}
+
+	return db
+}
+
+// TestManager_CreateStoreRetrieve exercises the full round trip against a
+// real pgvector instance: Create provisions the collection's table and
+// indexes on first use, Store embeds and inserts a document, and Retrieve
+// finds it back by vector similarity.
+func TestManager_CreateStoreRetrieve(t *testing.T) {
+	db := startPostgres(t)
+	emb := &fakeEmbedder{dims: 8}
+
+	mgr, err := NewManager(&ManagerConfig{DB: db, Embedding: emb})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	ctx := context.Background()
+	collection := "test_collection"
+
+	if err := mgr.Create(ctx, &searchstore.CreateRequest{CollectionName: collection}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// Create must be idempotent: re-running it against the same,
+	// already-correct collection should validate dimensions and succeed.
+	if err := mgr.Create(ctx, &searchstore.CreateRequest{CollectionName: collection}); err != nil {
+		t.Fatalf("second Create on an existing collection failed: %v", err)
+	}
+
+	ss, err := mgr.GetSearchStore(ctx, collection)
+	if err != nil {
+		t.Fatalf("GetSearchStore failed: %v", err)
+	}
+
+	doc := &schema.Document{ID: "doc-1", Content: "hello pgvector"}
+	if _, err := ss.Store(ctx, []*schema.Document{doc}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	got, err := ss.Retrieve(ctx, "hello pgvector")
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if len(got) == 0 {
+		t.Fatal("Retrieve returned no documents for a collection with exactly one stored document")
+	}
+	if got[0].ID != doc.ID {
+		t.Fatalf("Retrieve returned the wrong document, got=%s want=%s", got[0].ID, doc.ID)
+	}
+
+	if err := ss.Delete(ctx, []string{doc.ID}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := mgr.Drop(ctx, &searchstore.DropRequest{CollectionName: collection}); err != nil {
+		t.Fatalf("Drop failed: %v", err)
+	}
+}
+
+// TestManager_Create_DimensionMismatch asserts that creating a collection
+// against an embedder whose dimensionality doesn't match the existing
+// table's embedding column is rejected rather than silently corrupting
+// retrieval.
+func TestManager_Create_DimensionMismatch(t *testing.T) {
+	db := startPostgres(t)
+	ctx := context.Background()
+	collection := "test_dims_collection"
+
+	mgr, err := NewManager(&ManagerConfig{DB: db, Embedding: &fakeEmbedder{dims: 8}})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	if err := mgr.Create(ctx, &searchstore.CreateRequest{CollectionName: collection}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	mismatched, err := NewManager(&ManagerConfig{DB: db, Embedding: &fakeEmbedder{dims: 16}})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	if err := mismatched.Create(ctx, &searchstore.CreateRequest{CollectionName: collection}); err == nil {
+		t.Fatal("expected Create to reject a dimension mismatch against the existing table, got nil error")
+	}
+}
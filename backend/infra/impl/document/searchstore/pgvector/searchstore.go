@@ -0,0 +1,283 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pgvector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document"
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+	"github.com/coze-dev/coze-studio/backend/pkg/lang/ptr"
+)
+
+const (
+	topK = 10
+
+	// defaultRRFK / defaultRRFCandidateCap mirror the constants used by the
+	// OceanBase and Elasticsearch backends' hybrid search, so all three fuse
+	// their vector and text legs identically regardless of which one a
+	// deployment picks.
+	defaultRRFK            = 60
+	defaultRRFCandidateCap = 200
+)
+
+type pgvectorSearchStore struct {
+	config         *ManagerConfig
+	collectionName string
+	table          string
+	db             *gorm.DB
+}
+
+// rankedRow is one row of a single-leg ranked list, ahead of RRF fusion.
+type rankedRow struct {
+	doc  *schema.Document
+	rank int // 1-based rank within its source list
+}
+
+func (s *pgvectorSearchStore) Retrieve(ctx context.Context, query string, opts ...retriever.Option) ([]*schema.Document, error) {
+	options := retriever.GetCommonOptions(&retriever.Options{TopK: ptr.Of(topK)}, opts...)
+	implSpecOptions := retriever.GetImplSpecificOptions(&searchstore.RetrieverOptions{}, opts...)
+
+	k := defaultRRFK
+	cap_ := defaultRRFCandidateCap
+	if implSpecOptions.RRFK != nil {
+		k = *implSpecOptions.RRFK
+	}
+	if implSpecOptions.RRFCandidateCap != nil {
+		cap_ = *implSpecOptions.RRFCandidateCap
+	}
+
+	vectorRanked, err := s.vectorLeg(ctx, query, cap_, implSpecOptions)
+	if err != nil {
+		return nil, fmt.Errorf("[Retrieve] vector leg failed, %w", err)
+	}
+
+	textRanked, err := s.textLeg(ctx, query, cap_, implSpecOptions)
+	if err != nil {
+		return nil, fmt.Errorf("[Retrieve] text leg failed, %w", err)
+	}
+
+	return fuseRRF(vectorRanked, textRanked, k, *options.TopK), nil
+}
+
+// vectorLeg ranks rows by cosine distance on the embedding column alone
+// (pgvector's <=> operator), returning a 1-based ranked list so it can be
+// fused by rank rather than by raw distance.
+func (s *pgvectorSearchStore) vectorLeg(ctx context.Context, query string, limit int, implSpecOptions *searchstore.RetrieverOptions) ([]rankedRow, error) {
+	emb, err := s.config.Embedding.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("[vectorLeg] embed failed, %w", err)
+	}
+	if len(emb) != 1 {
+		return nil, fmt.Errorf("[vectorLeg] unexpected embedding size, expected=1, got=%d", len(emb))
+	}
+	vectorStr := toVectorLiteral(emb[0])
+
+	whereClause, whereArgs, err := buildFilterClause(implSpecOptions)
+	if err != nil {
+		return nil, fmt.Errorf("[vectorLeg] %w", err)
+	}
+	sql := fmt.Sprintf(`
+		SELECT id, content, creator_id, create_time, update_time
+		FROM %s
+		WHERE true %s
+		ORDER BY embedding <=> ?
+		LIMIT ?
+	`, s.table, whereClause)
+
+	args := append(whereArgs, vectorStr, limit)
+	return s.scanRanked(s.db.WithContext(ctx).Raw(sql, args...))
+}
+
+// textLeg ranks rows by ts_rank against the generated content_tsv column
+// alone, returning a 1-based ranked list.
+func (s *pgvectorSearchStore) textLeg(ctx context.Context, query string, limit int, implSpecOptions *searchstore.RetrieverOptions) ([]rankedRow, error) {
+	whereClause, whereArgs, err := buildFilterClause(implSpecOptions)
+	if err != nil {
+		return nil, fmt.Errorf("[textLeg] %w", err)
+	}
+	sql := fmt.Sprintf(`
+		SELECT id, content, creator_id, create_time, update_time
+		FROM %s
+		WHERE content_tsv @@ plainto_tsquery('english', ?) %s
+		ORDER BY ts_rank(content_tsv, plainto_tsquery('english', ?)) DESC
+		LIMIT ?
+	`, s.table, whereClause)
+
+	args := append([]interface{}{query}, whereArgs...)
+	args = append(args, query, limit)
+	return s.scanRanked(s.db.WithContext(ctx).Raw(sql, args...))
+}
+
+func (s *pgvectorSearchStore) scanRanked(tx *gorm.DB) ([]rankedRow, error) {
+	rows, err := tx.Rows()
+	if err != nil {
+		return nil, fmt.Errorf("query failed, %w", err)
+	}
+	defer rows.Close()
+
+	var ranked []rankedRow
+	rank := 0
+	for rows.Next() {
+		var id, content string
+		var creatorID int64
+		var createTime, updateTime int64
+
+		if err := rows.Scan(&id, &content, &creatorID, &createTime, &updateTime); err != nil {
+			return nil, fmt.Errorf("scan failed, %w", err)
+		}
+
+		rank++
+		doc := &schema.Document{
+			ID:      id,
+			Content: content,
+			MetaData: map[string]any{
+				document.MetaDataKeyCreatorID: creatorID,
+				"create_time":                 createTime,
+				"update_time":                 updateTime,
+			},
+		}
+		ranked = append(ranked, rankedRow{doc: doc, rank: rank})
+	}
+
+	return ranked, nil
+}
+
+// fuseRRF merges the vector and text legs by Reciprocal Rank Fusion, the
+// same strategy oceanbaseSearchStore.hybridSearch and the Elasticsearch
+// backend use, and returns the top n fused documents.
+func fuseRRF(vector, text []rankedRow, k int, n int) []*schema.Document {
+	type fused struct {
+		doc   *schema.Document
+		score float64
+	}
+
+	byID := make(map[string]*fused, len(vector)+len(text))
+	var order []string
+
+	addRanked := func(ranked []rankedRow) {
+		for _, r := range ranked {
+			f, ok := byID[r.doc.ID]
+			if !ok {
+				f = &fused{doc: r.doc}
+				byID[r.doc.ID] = f
+				order = append(order, r.doc.ID)
+			}
+			f.score += 1.0 / float64(k+r.rank)
+		}
+	}
+	addRanked(vector)
+	addRanked(text)
+
+	docs := make([]*schema.Document, 0, len(order))
+	for _, id := range order {
+		f := byID[id]
+		f.doc.WithScore(f.score)
+		docs = append(docs, f.doc)
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Score() > docs[j].Score()
+	})
+
+	if len(docs) > n {
+		docs = docs[:n]
+	}
+	return docs
+}
+
+func (s *pgvectorSearchStore) Store(ctx context.Context, docs []*schema.Document, opts ...indexer.Option) (ids []string, err error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	implSpecOptions := indexer.GetImplSpecificOptions(&searchstore.IndexerOptions{}, opts...)
+
+	defer func() {
+		if err != nil {
+			if implSpecOptions.ProgressBar != nil {
+				_ = implSpecOptions.ProgressBar.ReportError(err)
+			}
+		}
+	}()
+
+	ids = make([]string, 0, len(docs))
+	for _, doc := range docs {
+		emb, err := s.config.Embedding.EmbedStrings(ctx, []string{doc.Content})
+		if err != nil {
+			return nil, fmt.Errorf("[Store] embed failed, %w", err)
+		}
+		if len(emb) != 1 {
+			return nil, fmt.Errorf("[Store] unexpected embedding size, expected=1, got=%d", len(emb))
+		}
+
+		creatorID, _ := doc.MetaData[document.MetaDataKeyCreatorID].(int64)
+
+		sql := fmt.Sprintf(`
+			INSERT INTO %s (id, content, embedding, metadata, creator_id, update_time)
+			VALUES (?, ?, ?, ?, ?, extract(epoch from now()))
+			ON CONFLICT (id) DO UPDATE SET
+				content = EXCLUDED.content,
+				embedding = EXCLUDED.embedding,
+				metadata = EXCLUDED.metadata,
+				update_time = EXCLUDED.update_time
+		`, s.table)
+
+		if err := s.db.WithContext(ctx).Exec(sql, doc.ID, doc.Content, toVectorLiteral(emb[0]), doc.MetaData, creatorID).Error; err != nil {
+			return nil, fmt.Errorf("[Store] upsert row failed, %w", err)
+		}
+
+		ids = append(ids, doc.ID)
+
+		if implSpecOptions.ProgressBar != nil {
+			if err = implSpecOptions.ProgressBar.AddN(1); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+func (s *pgvectorSearchStore) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := s.db.WithContext(ctx).Exec(fmt.Sprintf("DELETE FROM %s WHERE id IN (?)", s.table), ids).Error; err != nil {
+		return fmt.Errorf("[Delete] delete rows failed, %w", err)
+	}
+	return nil
+}
+
+// toVectorLiteral formats a dense embedding as pgvector's textual input
+// format, e.g. "[0.1,0.2,0.3]".
+func toVectorLiteral(v []float64) string {
+	parts := make([]string, len(v))
+	for i, f := range v {
+		parts[i] = fmt.Sprintf("%v", f)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package qdrant
+
+import (
+	"fmt"
+
+	"github.com/qdrant/go-client/qdrant"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+)
+
+// indexedFields mirrors sqlfilter's indexedColumns: payload keys every point
+// carries directly, as opposed to arbitrary metadata keys which live nested
+// under the "metadata" payload field.
+var indexedFields = map[string]bool{
+	"creator_id":  true,
+	"create_time": true,
+	"update_time": true,
+}
+
+// buildFilter translates implSpecOptions.Filter into a *qdrant.Filter,
+// Qdrant's payload-filter analogue of sqlfilter.Build for the SQL backends.
+func buildFilter(implSpecOptions *searchstore.RetrieverOptions) *qdrant.Filter {
+	if implSpecOptions == nil || implSpecOptions.Filter == nil {
+		return nil
+	}
+	return build(implSpecOptions.Filter)
+}
+
+func build(f *searchstore.Filter) *qdrant.Filter {
+	if f == nil {
+		return nil
+	}
+
+	out := &qdrant.Filter{}
+
+	for _, sub := range f.And {
+		if c := build(sub); c != nil {
+			out.Must = append(out.Must, qdrant.NewFilterAsCondition(c))
+		}
+	}
+	for _, sub := range f.Or {
+		if c := build(sub); c != nil {
+			out.Should = append(out.Should, qdrant.NewFilterAsCondition(c))
+		}
+	}
+	if f.Not != nil {
+		if c := build(f.Not); c != nil {
+			out.MustNot = append(out.MustNot, qdrant.NewFilterAsCondition(c))
+		}
+	}
+	if f.Field != "" {
+		out.Must = append(out.Must, buildCondition(f)...)
+	}
+
+	if len(out.Must) == 0 && len(out.Should) == 0 && len(out.MustNot) == 0 {
+		return nil
+	}
+	return out
+}
+
+// buildCondition compiles a single leaf predicate (range / equality / IN /
+// null-check) on f.Field into one or more Qdrant conditions, ANDed together
+// by the caller.
+func buildCondition(f *searchstore.Filter) []*qdrant.Condition {
+	key := resolveKey(f.Field)
+
+	if f.IsNull != nil {
+		if *f.IsNull {
+			return []*qdrant.Condition{qdrant.NewIsNull(key)}
+		}
+		return []*qdrant.Condition{qdrant.NewFilterAsCondition(&qdrant.Filter{
+			MustNot: []*qdrant.Condition{qdrant.NewIsNull(key)},
+		})}
+	}
+
+	var conds []*qdrant.Condition
+
+	if f.Eq != nil {
+		conds = append(conds, matchCondition(key, f.Eq))
+	}
+	if len(f.In) > 0 {
+		conds = append(conds, qdrant.NewMatchKeywords(key, toStrings(f.In)...))
+	}
+	if f.Gt != nil || f.Gte != nil || f.Lt != nil || f.Lte != nil {
+		r := &qdrant.Range{}
+		if f.Gt != nil {
+			r.Gt = qdrant.PtrOf(toFloat64(f.Gt))
+		}
+		if f.Gte != nil {
+			r.Gte = qdrant.PtrOf(toFloat64(f.Gte))
+		}
+		if f.Lt != nil {
+			r.Lt = qdrant.PtrOf(toFloat64(f.Lt))
+		}
+		if f.Lte != nil {
+			r.Lte = qdrant.PtrOf(toFloat64(f.Lte))
+		}
+		conds = append(conds, qdrant.NewRange(key, r))
+	}
+
+	return conds
+}
+
+func matchCondition(key string, v interface{}) *qdrant.Condition {
+	switch val := v.(type) {
+	case string:
+		return qdrant.NewMatch(key, val)
+	case bool:
+		return qdrant.NewMatchBool(key, val)
+	case int, int32, int64:
+		return qdrant.NewMatchInt(key, toInt64(val))
+	default:
+		return qdrant.NewMatch(key, fmt.Sprintf("%v", val))
+	}
+}
+
+func toStrings(vs []interface{}) []string {
+	out := make([]string, len(vs))
+	for i, v := range vs {
+		out[i] = fmt.Sprintf("%v", v)
+	}
+	return out
+}
+
+func toFloat64(v interface{}) float64 {
+	switch val := v.(type) {
+	case float64:
+		return val
+	case float32:
+		return float64(val)
+	case int:
+		return float64(val)
+	case int64:
+		return float64(val)
+	default:
+		return 0
+	}
+}
+
+func toInt64(v interface{}) int64 {
+	switch val := v.(type) {
+	case int:
+		return int64(val)
+	case int32:
+		return int64(val)
+	case int64:
+		return val
+	default:
+		return 0
+	}
+}
+
+// resolveKey maps a filter field name to the payload path that reads it:
+// indexed fields are top-level payload keys, everything else is assumed to
+// be a key nested inside the "metadata" payload object.
+func resolveKey(field string) string {
+	if indexedFields[field] {
+		return field
+	}
+	return "metadata." + field
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package qdrant implements searchstore.Manager on top of Qdrant's gRPC API.
+// Each collection carries two named vectors, "dense" (the configured
+// embedder's output, used for kNN) and "sparse" (a hashed bag-of-words
+// vector, used for a BM25-ish text leg), fused at query time with the same
+// RRF strategy the OceanBase/Elasticsearch/pgvector backends use. Metadata
+// filters are mapped onto Qdrant payload filters from the same
+// searchstore.Filter DSL the SQL backends consume.
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qdrant/go-client/qdrant"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+	"github.com/coze-dev/coze-studio/backend/infra/contract/embedding"
+)
+
+const (
+	denseVectorName  = "dense"
+	sparseVectorName = "sparse"
+)
+
+type ManagerConfig struct {
+	Client    *qdrant.Client     // required
+	Embedding embedding.Embedder // required
+
+	// EnableHybrid additionally creates and populates the sparse named
+	// vector, so Retrieve can fuse a text-ish leg alongside the dense kNN
+	// leg.
+	EnableHybrid bool
+
+	// SparseEmbedding produces the sparse leg's vectors when EnableHybrid is
+	// set. If nil, a hashed bag-of-words vector is used instead so hybrid
+	// mode still works without a SPLADE/BM25 embedder configured.
+	SparseEmbedding embedding.SparseEmbedder
+}
+
+func NewManager(config *ManagerConfig) (searchstore.Manager, error) {
+	if config.Client == nil {
+		return nil, fmt.Errorf("[NewManager] qdrant client not provided")
+	}
+	if config.Embedding == nil {
+		return nil, fmt.Errorf("[NewManager] qdrant embedder not provided")
+	}
+
+	return &qdrantManager{config: config}, nil
+}
+
+type qdrantManager struct {
+	config *ManagerConfig
+}
+
+func (m *qdrantManager) Create(ctx context.Context, req *searchstore.CreateRequest) error {
+	exists, err := m.config.Client.CollectionExists(ctx, req.CollectionName)
+	if err != nil {
+		return fmt.Errorf("[Create] check collection existence failed, %w", err)
+	}
+	if exists {
+		return m.validateDimensions(ctx, req.CollectionName)
+	}
+
+	vectorsConfig := qdrant.NewVectorsConfigMap(map[string]*qdrant.VectorParams{
+		denseVectorName: {
+			Size:     uint64(m.config.Embedding.Dimensions()),
+			Distance: qdrant.Distance_Cosine,
+		},
+	})
+
+	createReq := &qdrant.CreateCollection{
+		CollectionName: req.CollectionName,
+		VectorsConfig:  vectorsConfig,
+	}
+
+	if m.config.EnableHybrid {
+		createReq.SparseVectorsConfig = qdrant.NewSparseVectorsConfig(map[string]*qdrant.SparseVectorParams{
+			sparseVectorName: {},
+		})
+	}
+
+	if err := m.config.Client.CreateCollection(ctx, createReq); err != nil {
+		return fmt.Errorf("[Create] create collection failed, %w", err)
+	}
+
+	return nil
+}
+
+// validateDimensions guards against a knowledge base being re-pointed at an
+// embedder with a different dimensionality than the collection it was
+// created with, the same check the pgvector backend makes.
+func (m *qdrantManager) validateDimensions(ctx context.Context, collectionName string) error {
+	info, err := m.config.Client.GetCollectionInfo(ctx, collectionName)
+	if err != nil {
+		return fmt.Errorf("[Create] read existing collection info failed, %w", err)
+	}
+
+	params := info.GetConfig().GetParams().GetVectorsConfig().GetParamsMap().GetMap()[denseVectorName]
+	wantDims := uint64(m.config.Embedding.Dimensions())
+	if params == nil || params.GetSize() != wantDims {
+		return fmt.Errorf("[Create] embedding dimension mismatch for collection %s, configured=%d", collectionName, wantDims)
+	}
+	return nil
+}
+
+func (m *qdrantManager) Drop(ctx context.Context, req *searchstore.DropRequest) error {
+	if err := m.config.Client.DeleteCollection(ctx, req.CollectionName); err != nil {
+		return fmt.Errorf("[Drop] delete collection failed, %w", err)
+	}
+	return nil
+}
+
+func (m *qdrantManager) GetType() searchstore.SearchStoreType {
+	return searchstore.TypeVectorStore
+}
+
+func (m *qdrantManager) GetSearchStore(ctx context.Context, collectionName string) (searchstore.SearchStore, error) {
+	return &qdrantSearchStore{
+		config:         m.config,
+		collectionName: collectionName,
+	}, nil
+}
+
+func (m *qdrantManager) GetEmbedding() embedding.Embedder {
+	return m.config.Embedding
+}
@@ -0,0 +1,170 @@
+//go:build integration
+
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	qdrantclient "github.com/qdrant/go-client/qdrant"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+)
+
+// fakeEmbedder is a deterministic stand-in for embedding.Embedder: each
+// string's vector is derived from its own byte sum, so Retrieve can be
+// exercised without a real embedding provider.
+type fakeEmbedder struct{ dims int }
+
+func (f *fakeEmbedder) Dimensions() int { return f.dims }
+
+func (f *fakeEmbedder) EmbedStrings(ctx context.Context, texts []string) ([][]float64, error) {
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		vec := make([]float64, f.dims)
+		var sum float64
+		for _, b := range []byte(text) {
+			sum += float64(b)
+		}
+		vec[0] = sum
+		out[i] = vec
+	}
+	return out, nil
+}
+
+// startQdrant brings up a Qdrant container via testcontainers-go and
+// returns a gRPC client connected to it.
+func startQdrant(t *testing.T) *qdrantclient.Client {
+	t.Helper()
+	ctx := context.Background()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "qdrant/qdrant:latest",
+			ExposedPorts: []string{"6334/tcp"},
+			WaitingFor:   wait.ForListeningPort("6334/tcp").WithStartupTimeout(60 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("start qdrant container failed: %v", err)
+	}
+	t.Cleanup(func() { _ = container.Terminate(ctx) })
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("get container host failed: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "6334/tcp")
+	if err != nil {
+		t.Fatalf("get mapped port failed: %v", err)
+	}
+
+	client, err := qdrantclient.NewClient(&qdrantclient.Config{Host: fmt.Sprintf("%s:%d", host, port.Int())})
+	if err != nil {
+		t.Fatalf("connect to qdrant failed: %v", err)
+	}
+
+	return client
+}
+
+// TestManager_CreateStoreRetrieve exercises the full round trip against a
+// real Qdrant instance: Create provisions the collection's dense (and,
+// with EnableHybrid, sparse) named vectors on first use, Store embeds and
+// upserts a document, and Retrieve finds it back by vector similarity.
+func TestManager_CreateStoreRetrieve(t *testing.T) {
+	client := startQdrant(t)
+	emb := &fakeEmbedder{dims: 8}
+
+	mgr, err := NewManager(&ManagerConfig{Client: client, Embedding: emb})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	ctx := context.Background()
+	collection := "test_collection"
+
+	if err := mgr.Create(ctx, &searchstore.CreateRequest{CollectionName: collection}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// Create must be idempotent: re-running it against the same,
+	// already-correct collection should validate dimensions and succeed.
+	if err := mgr.Create(ctx, &searchstore.CreateRequest{CollectionName: collection}); err != nil {
+		t.Fatalf("second Create on an existing collection failed: %v", err)
+	}
+
+	ss, err := mgr.GetSearchStore(ctx, collection)
+	if err != nil {
+		t.Fatalf("GetSearchStore failed: %v", err)
+	}
+
+	doc := &schema.Document{ID: "doc-1", Content: "hello qdrant"}
+	if _, err := ss.Store(ctx, []*schema.Document{doc}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	got, err := ss.Retrieve(ctx, "hello qdrant")
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if len(got) == 0 {
+		t.Fatal("Retrieve returned no documents for a collection with exactly one stored document")
+	}
+	if got[0].ID != doc.ID {
+		t.Fatalf("Retrieve returned the wrong document, got=%s want=%s", got[0].ID, doc.ID)
+	}
+
+	if err := ss.Delete(ctx, []string{doc.ID}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := mgr.Drop(ctx, &searchstore.DropRequest{CollectionName: collection}); err != nil {
+		t.Fatalf("Drop failed: %v", err)
+	}
+}
+
+// TestManager_Create_DimensionMismatch asserts that creating a collection
+// against an embedder whose dimensionality doesn't match the existing
+// collection's dense vector is rejected rather than silently corrupting
+// retrieval.
+func TestManager_Create_DimensionMismatch(t *testing.T) {
+	client := startQdrant(t)
+	ctx := context.Background()
+	collection := "test_dims_collection"
+
+	mgr, err := NewManager(&ManagerConfig{Client: client, Embedding: &fakeEmbedder{dims: 8}})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	if err := mgr.Create(ctx, &searchstore.CreateRequest{CollectionName: collection}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	mismatched, err := NewManager(&ManagerConfig{Client: client, Embedding: &fakeEmbedder{dims: 16}})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	if err := mismatched.Create(ctx, &searchstore.CreateRequest{CollectionName: collection}); err == nil {
+		t.Fatal("expected Create to reject a dimension mismatch against the existing collection, got nil error")
+	}
+}
@@ -0,0 +1,366 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	"github.com/qdrant/go-client/qdrant"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document"
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+	"github.com/coze-dev/coze-studio/backend/infra/contract/embedding"
+	"github.com/coze-dev/coze-studio/backend/pkg/lang/ptr"
+)
+
+const (
+	topK = 10
+
+	// defaultRRFK / defaultRRFCandidateCap mirror the constants used by the
+	// OceanBase/Elasticsearch/pgvector backends' hybrid search, so every
+	// backend fuses its legs identically regardless of which one a
+	// deployment picks.
+	defaultRRFK            = 60
+	defaultRRFCandidateCap = 200
+)
+
+type qdrantSearchStore struct {
+	config         *ManagerConfig
+	collectionName string
+}
+
+// rankedPoint is one row of a single-leg ranked list, ahead of RRF fusion.
+type rankedPoint struct {
+	doc  *schema.Document
+	rank int // 1-based rank within its source list
+}
+
+func (s *qdrantSearchStore) Retrieve(ctx context.Context, query string, opts ...retriever.Option) ([]*schema.Document, error) {
+	options := retriever.GetCommonOptions(&retriever.Options{TopK: ptr.Of(topK)}, opts...)
+	implSpecOptions := retriever.GetImplSpecificOptions(&searchstore.RetrieverOptions{}, opts...)
+
+	k := defaultRRFK
+	cap_ := defaultRRFCandidateCap
+	if implSpecOptions.RRFK != nil {
+		k = *implSpecOptions.RRFK
+	}
+	if implSpecOptions.RRFCandidateCap != nil {
+		cap_ = *implSpecOptions.RRFCandidateCap
+	}
+
+	filter := buildFilter(implSpecOptions)
+
+	denseRanked, err := s.denseLeg(ctx, query, cap_, filter)
+	if err != nil {
+		return nil, fmt.Errorf("[Retrieve] dense leg failed, %w", err)
+	}
+
+	if !s.config.EnableHybrid {
+		return toTopN(denseRanked, *options.TopK), nil
+	}
+
+	sparseRanked, err := s.sparseLeg(ctx, query, cap_, filter)
+	if err != nil {
+		return nil, fmt.Errorf("[Retrieve] sparse leg failed, %w", err)
+	}
+
+	return fuseRRF(denseRanked, sparseRanked, k, *options.TopK), nil
+}
+
+func (s *qdrantSearchStore) denseLeg(ctx context.Context, query string, limit int, filter *qdrant.Filter) ([]rankedPoint, error) {
+	emb, err := s.config.Embedding.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("[denseLeg] embed failed, %w", err)
+	}
+	if len(emb) != 1 {
+		return nil, fmt.Errorf("[denseLeg] unexpected embedding size, expected=1, got=%d", len(emb))
+	}
+
+	dense := make([]float32, len(emb[0]))
+	for i, f := range emb[0] {
+		dense[i] = float32(f)
+	}
+
+	points, err := s.config.Client.Query(ctx, &qdrant.QueryPoints{
+		CollectionName: s.collectionName,
+		Query:          qdrant.NewQuery(dense...),
+		Using:          qdrant.PtrOf(denseVectorName),
+		Filter:         filter,
+		Limit:          qdrant.PtrOf(uint64(limit)),
+		WithPayload:    qdrant.NewWithPayload(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[denseLeg] query failed, %w", err)
+	}
+
+	return toRanked(points), nil
+}
+
+// sparseLeg ranks points by the sparse vector alone, so collections created
+// with EnableHybrid get a text-ish leg. It uses the configured
+// embedding.SparseEmbedder (SPLADE, BM25, ...) when set, falling back to a
+// hashed bag-of-words vector so hybrid mode still works without one.
+func (s *qdrantSearchStore) sparseLeg(ctx context.Context, query string, limit int, filter *qdrant.Filter) ([]rankedPoint, error) {
+	idx, vals, err := s.sparseVector(ctx, query, false)
+	if err != nil {
+		return nil, fmt.Errorf("[sparseLeg] sparse embed failed, %w", err)
+	}
+
+	points, err := s.config.Client.Query(ctx, &qdrant.QueryPoints{
+		CollectionName: s.collectionName,
+		Query:          qdrant.NewQuerySparse(idx, vals),
+		Using:          qdrant.PtrOf(sparseVectorName),
+		Filter:         filter,
+		Limit:          qdrant.PtrOf(uint64(limit)),
+		WithPayload:    qdrant.NewWithPayload(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[sparseLeg] query failed, %w", err)
+	}
+
+	return toRanked(points), nil
+}
+
+// sparseScorer is implemented by embedding.SparseEmbedder backends that keep
+// mutable corpus statistics (bm25Embedder) and so need a read-only scoring
+// path distinct from their indexing-time EmbedStringsSparse. Backends
+// without corpus state (splade's stateless HTTP embedder) don't need to
+// implement it - EmbedStringsSparse is already safe to call at query time.
+type sparseScorer interface {
+	ScoreStringsSparse(ctx context.Context, texts []string) ([]embedding.SparseVector, error)
+}
+
+// sparseVector computes one text's sparse vector, preferring the configured
+// SparseEmbedding when set and falling back to sparsify otherwise. forIndexing
+// distinguishes Store's indexing-time call, which must fold the text into
+// any corpus statistics the embedder keeps, from query-time callers
+// (sparseLeg), which must not - indexing a search query into the corpus
+// would corrupt the very statistics it's being scored against.
+func (s *qdrantSearchStore) sparseVector(ctx context.Context, text string, forIndexing bool) ([]uint32, []float32, error) {
+	if s.config.SparseEmbedding == nil {
+		idx, vals := sparsify(text)
+		return idx, vals, nil
+	}
+
+	var svs []embedding.SparseVector
+	var err error
+	if scorer, ok := s.config.SparseEmbedding.(sparseScorer); ok && !forIndexing {
+		svs, err = scorer.ScoreStringsSparse(ctx, []string{text})
+	} else {
+		svs, err = s.config.SparseEmbedding.EmbedStringsSparse(ctx, []string{text})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(svs) != 1 {
+		return nil, nil, fmt.Errorf("unexpected sparse embedding size, expected=1, got=%d", len(svs))
+	}
+	return svs[0].Indices, svs[0].Values, nil
+}
+
+func toRanked(points []*qdrant.ScoredPoint) []rankedPoint {
+	ranked := make([]rankedPoint, 0, len(points))
+	for i, p := range points {
+		ranked = append(ranked, rankedPoint{doc: toDocument(p.GetId(), p.GetPayload()), rank: i + 1})
+	}
+	return ranked
+}
+
+func toDocument(id *qdrant.PointId, payload map[string]*qdrant.Value) *schema.Document {
+	content := payload["content"].GetStringValue()
+
+	creatorID := payload[document.MetaDataKeyCreatorID].GetIntegerValue()
+	createTime := payload["create_time"].GetIntegerValue()
+	updateTime := payload["update_time"].GetIntegerValue()
+
+	return &schema.Document{
+		ID:      pointIDString(id),
+		Content: content,
+		MetaData: map[string]any{
+			document.MetaDataKeyCreatorID: creatorID,
+			"create_time":                 createTime,
+			"update_time":                 updateTime,
+		},
+	}
+}
+
+func toTopN(ranked []rankedPoint, n int) []*schema.Document {
+	docs := make([]*schema.Document, 0, len(ranked))
+	for _, r := range ranked {
+		docs = append(docs, r.doc)
+	}
+	if len(docs) > n {
+		docs = docs[:n]
+	}
+	return docs
+}
+
+// fuseRRF merges the dense and sparse legs by Reciprocal Rank Fusion, the
+// same strategy every other searchstore backend in this tree uses, and
+// returns the top n fused documents.
+func fuseRRF(dense, sparse []rankedPoint, k int, n int) []*schema.Document {
+	type fused struct {
+		doc   *schema.Document
+		score float64
+	}
+
+	byID := make(map[string]*fused, len(dense)+len(sparse))
+	var order []string
+
+	addRanked := func(ranked []rankedPoint) {
+		for _, r := range ranked {
+			f, ok := byID[r.doc.ID]
+			if !ok {
+				f = &fused{doc: r.doc}
+				byID[r.doc.ID] = f
+				order = append(order, r.doc.ID)
+			}
+			f.score += 1.0 / float64(k+r.rank)
+		}
+	}
+	addRanked(dense)
+	addRanked(sparse)
+
+	docs := make([]*schema.Document, 0, len(order))
+	for _, id := range order {
+		f := byID[id]
+		f.doc.WithScore(f.score)
+		docs = append(docs, f.doc)
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Score() > docs[j].Score()
+	})
+
+	if len(docs) > n {
+		docs = docs[:n]
+	}
+	return docs
+}
+
+func (s *qdrantSearchStore) Store(ctx context.Context, docs []*schema.Document, opts ...indexer.Option) (ids []string, err error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	implSpecOptions := indexer.GetImplSpecificOptions(&searchstore.IndexerOptions{}, opts...)
+
+	defer func() {
+		if err != nil {
+			if implSpecOptions.ProgressBar != nil {
+				_ = implSpecOptions.ProgressBar.ReportError(err)
+			}
+		}
+	}()
+
+	points := make([]*qdrant.PointStruct, 0, len(docs))
+	ids = make([]string, 0, len(docs))
+	for _, doc := range docs {
+		emb, err := s.config.Embedding.EmbedStrings(ctx, []string{doc.Content})
+		if err != nil {
+			return nil, fmt.Errorf("[Store] embed failed, %w", err)
+		}
+		if len(emb) != 1 {
+			return nil, fmt.Errorf("[Store] unexpected embedding size, expected=1, got=%d", len(emb))
+		}
+
+		dense := make([]float32, len(emb[0]))
+		for i, f := range emb[0] {
+			dense[i] = float32(f)
+		}
+
+		vectors := qdrant.NewVectorsMap(map[string]*qdrant.Vector{
+			denseVectorName: qdrant.NewVector(dense...),
+		})
+		if s.config.EnableHybrid {
+			idx, vals, err := s.sparseVector(ctx, doc.Content, true)
+			if err != nil {
+				return nil, fmt.Errorf("[Store] sparse embed failed, %w", err)
+			}
+			vectors.GetVectors()[sparseVectorName] = qdrant.NewVectorSparse(idx, vals)
+		}
+
+		creatorID, _ := doc.MetaData[document.MetaDataKeyCreatorID].(int64)
+
+		points = append(points, &qdrant.PointStruct{
+			Id:      qdrant.NewIDUUID(doc.ID),
+			Vectors: vectors,
+			Payload: qdrant.NewValueMap(payloadFor(doc, creatorID)),
+		})
+
+		ids = append(ids, doc.ID)
+	}
+
+	if _, err = s.config.Client.Upsert(ctx, &qdrant.UpsertPoints{
+		CollectionName: s.collectionName,
+		Points:         points,
+	}); err != nil {
+		return nil, fmt.Errorf("[Store] upsert points failed, %w", err)
+	}
+
+	if implSpecOptions.ProgressBar != nil {
+		if err = implSpecOptions.ProgressBar.AddN(len(points)); err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, nil
+}
+
+func payloadFor(doc *schema.Document, creatorID int64) map[string]any {
+	now := time.Now().Unix()
+	payload := map[string]any{
+		"content":                     doc.Content,
+		document.MetaDataKeyCreatorID: creatorID,
+		"create_time":                 now,
+		"update_time":                 now,
+		"metadata":                    doc.MetaData,
+	}
+	return payload
+}
+
+func (s *qdrantSearchStore) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pointIDs := make([]*qdrant.PointId, len(ids))
+	for i, id := range ids {
+		pointIDs[i] = qdrant.NewIDUUID(id)
+	}
+
+	if _, err := s.config.Client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: s.collectionName,
+		Points:         qdrant.NewPointsSelector(pointIDs...),
+	}); err != nil {
+		return fmt.Errorf("[Delete] delete points failed, %w", err)
+	}
+	return nil
+}
+
+func pointIDString(id *qdrant.PointId) string {
+	if uuid := id.GetUuid(); uuid != "" {
+		return uuid
+	}
+	return fmt.Sprintf("%d", id.GetNum())
+}
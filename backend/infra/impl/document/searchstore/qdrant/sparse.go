@@ -0,0 +1,50 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package qdrant
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// sparseDims bounds the hashed term space the bag-of-words sparse vector is
+// projected into. Collisions are acceptable: this is a stand-in text leg for
+// hybrid retrieval, not a learned sparse embedding.
+const sparseDims = 1 << 18
+
+// sparsify turns text into a hashed, term-frequency-weighted sparse vector:
+// each distinct token hashes to an index in [0, sparseDims), weighted by how
+// often it appears. It's the fallback sparseVector uses when no
+// embedding.SparseEmbedder is configured, so hybrid mode still works without
+// a SPLADE/BM25 embedder wired up.
+func sparsify(text string) (indices []uint32, values []float32) {
+	counts := make(map[uint32]float32)
+	for _, tok := range strings.Fields(strings.ToLower(text)) {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(tok))
+		idx := h.Sum32() % sparseDims
+		counts[idx]++
+	}
+
+	indices = make([]uint32, 0, len(counts))
+	values = make([]float32, 0, len(counts))
+	for idx, count := range counts {
+		indices = append(indices, idx)
+		values = append(values, count)
+	}
+	return indices, values
+}
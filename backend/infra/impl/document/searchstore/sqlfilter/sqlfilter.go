@@ -0,0 +1,219 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sqlfilter translates a searchstore.Filter into a parameterized SQL
+// WHERE fragment. It is shared by every SQL-backed searchstore.Manager
+// (OceanBase today, PGVector / Milvus tomorrow) so the filter DSL only needs
+// to be compiled to SQL once.
+package sqlfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/document/searchstore"
+)
+
+// indexedColumns lists the physical columns every SQL searchstore table is
+// expected to expose, as opposed to arbitrary metadata keys which live in a
+// JSON/metadata column and must be addressed via metadataColumn.
+var indexedColumns = map[string]bool{
+	"creator_id":  true,
+	"create_time": true,
+	"update_time": true,
+}
+
+// metadataColumn is the column holding arbitrary per-document metadata. Field
+// names that are not in indexedColumns are assumed to be keys within it.
+const metadataColumn = "metadata"
+
+// fieldNameRe allow-lists the *shape* of a field name before it's
+// interpolated into the JSON_EXTRACT path expression: f.Field comes from
+// caller-supplied filters, not a fixed schema, so there's no enumerated list
+// to check it against. Rejecting anything that isn't a plain identifier is
+// what actually closes the injection hole (quotes, `$`/`.` path syntax,
+// whitespace) rather than just relocating it. Mirrors columnNameRe in
+// infra/impl/search/oceanbase/oceanbase_search.go.
+var fieldNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Build compiles f into a SQL fragment starting with "AND", ready to be
+// appended after the collection_name predicate, plus its positional args in
+// the order they appear in the fragment. It returns ("", nil, nil) for a nil
+// or empty filter, and an error if any predicate's field name isn't a valid
+// identifier.
+func Build(f *searchstore.Filter) (string, []interface{}, error) {
+	clause, args, err := buildFilter(f)
+	if err != nil {
+		return "", nil, err
+	}
+	if clause == "" {
+		return "", nil, nil
+	}
+	return "AND " + clause, args, nil
+}
+
+func buildFilter(f *searchstore.Filter) (string, []interface{}, error) {
+	if f == nil {
+		return "", nil, nil
+	}
+
+	var clauses []string
+	var args []interface{}
+
+	if len(f.And) > 0 {
+		sub, subArgs, err := buildComposite(f.And, " AND ")
+		if err != nil {
+			return "", nil, err
+		}
+		if sub != "" {
+			clauses = append(clauses, sub)
+			args = append(args, subArgs...)
+		}
+	}
+
+	if len(f.Or) > 0 {
+		sub, subArgs, err := buildComposite(f.Or, " OR ")
+		if err != nil {
+			return "", nil, err
+		}
+		if sub != "" {
+			clauses = append(clauses, sub)
+			args = append(args, subArgs...)
+		}
+	}
+
+	if f.Not != nil {
+		sub, subArgs, err := buildFilter(f.Not)
+		if err != nil {
+			return "", nil, err
+		}
+		if sub != "" {
+			clauses = append(clauses, "NOT ("+sub+")")
+			args = append(args, subArgs...)
+		}
+	}
+
+	if f.Field != "" {
+		sub, subArgs, err := buildPredicate(f)
+		if err != nil {
+			return "", nil, err
+		}
+		if sub != "" {
+			clauses = append(clauses, sub)
+			args = append(args, subArgs...)
+		}
+	}
+
+	if len(clauses) == 0 {
+		return "", nil, nil
+	}
+	if len(clauses) == 1 {
+		return clauses[0], args, nil
+	}
+	return "(" + strings.Join(clauses, " AND ") + ")", args, nil
+}
+
+func buildComposite(filters []*searchstore.Filter, sep string) (string, []interface{}, error) {
+	var clauses []string
+	var args []interface{}
+	for _, sub := range filters {
+		clause, subArgs, err := buildFilter(sub)
+		if err != nil {
+			return "", nil, err
+		}
+		if clause == "" {
+			continue
+		}
+		clauses = append(clauses, clause)
+		args = append(args, subArgs...)
+	}
+	if len(clauses) == 0 {
+		return "", nil, nil
+	}
+	return "(" + strings.Join(clauses, sep) + ")", args, nil
+}
+
+// buildPredicate compiles a single leaf predicate (range / equality / IN /
+// null-check) on f.Field into SQL.
+func buildPredicate(f *searchstore.Filter) (string, []interface{}, error) {
+	column, err := resolveColumn(f.Field)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if f.IsNull != nil {
+		if *f.IsNull {
+			return fmt.Sprintf("%s IS NULL", column), nil, nil
+		}
+		return fmt.Sprintf("%s IS NOT NULL", column), nil, nil
+	}
+
+	var clauses []string
+	var args []interface{}
+
+	if f.Eq != nil {
+		clauses = append(clauses, fmt.Sprintf("%s = ?", column))
+		args = append(args, f.Eq)
+	}
+	if len(f.In) > 0 {
+		placeholders := make([]string, len(f.In))
+		for i, v := range f.In {
+			placeholders[i] = "?"
+			args = append(args, v)
+		}
+		clauses = append(clauses, fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ",")))
+	}
+	if f.Gt != nil {
+		clauses = append(clauses, fmt.Sprintf("%s > ?", column))
+		args = append(args, f.Gt)
+	}
+	if f.Gte != nil {
+		clauses = append(clauses, fmt.Sprintf("%s >= ?", column))
+		args = append(args, f.Gte)
+	}
+	if f.Lt != nil {
+		clauses = append(clauses, fmt.Sprintf("%s < ?", column))
+		args = append(args, f.Lt)
+	}
+	if f.Lte != nil {
+		clauses = append(clauses, fmt.Sprintf("%s <= ?", column))
+		args = append(args, f.Lte)
+	}
+
+	if len(clauses) == 0 {
+		return "", nil, nil
+	}
+	if len(clauses) == 1 {
+		return clauses[0], args, nil
+	}
+	return "(" + strings.Join(clauses, " AND ") + ")", args, nil
+}
+
+// resolveColumn maps a filter field name to the SQL expression that reads it:
+// indexed columns are addressed directly, everything else is assumed to be a
+// key inside the JSON metadata column. Rejects any field that isn't a plain
+// identifier before interpolating it, since it's otherwise spliced straight
+// into the quoted JSON path.
+func resolveColumn(field string) (string, error) {
+	if indexedColumns[field] {
+		return field, nil
+	}
+	if !fieldNameRe.MatchString(field) {
+		return "", fmt.Errorf("invalid filter field name %q", field)
+	}
+	return fmt.Sprintf("JSON_EXTRACT(%s, '$.%s')", metadataColumn, field), nil
+}
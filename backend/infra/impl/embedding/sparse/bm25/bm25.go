@@ -0,0 +1,178 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package bm25 implements embedding.SparseEmbedder locally, for corpora
+// where no SPLADE-style service is available: EmbedStringsSparse both
+// scores the given texts against Okapi BM25 statistics and folds them into
+// those same statistics, so the corpus's term weights sharpen as more
+// documents are indexed. ScoreStringsSparse scores without folding in, for
+// callers (query-time retrieval) that must not mutate the corpus. There is
+// no training step and no external dependency, at the cost of idf drifting
+// slightly while a knowledge base is still small.
+package bm25
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/embedding"
+)
+
+const (
+	defaultK1 = 1.2
+	defaultB  = 0.75
+)
+
+type Config struct {
+	K1 float64 // term frequency saturation, defaults to defaultK1
+	B  float64 // length normalization strength, defaults to defaultB
+}
+
+type bm25Embedder struct {
+	k1 float64
+	b  float64
+
+	mu       sync.Mutex
+	termIDs  map[string]uint32
+	df       map[string]int // document frequency per term
+	docCount int
+	totalLen int
+}
+
+// NewEmbedder builds a local, stateful BM25 embedding.SparseEmbedder.
+func NewEmbedder(cfg Config) embedding.SparseEmbedder {
+	if cfg.K1 <= 0 {
+		cfg.K1 = defaultK1
+	}
+	if cfg.B <= 0 {
+		cfg.B = defaultB
+	}
+
+	return &bm25Embedder{
+		k1:      cfg.K1,
+		b:       cfg.B,
+		termIDs: make(map[string]uint32),
+		df:      make(map[string]int),
+	}
+}
+
+// EmbedStringsSparse is the indexing-time path: it folds texts into the
+// corpus statistics (via observe) before scoring them, so the corpus's idf
+// and avgdl sharpen as more documents are stored. Only call this for text
+// that is actually being indexed - calling it at query time would
+// permanently count the query into docCount/df/totalLen and allocate
+// termIDs for query-only terms that were never indexed. Query-time callers
+// should use ScoreStringsSparse instead.
+func (e *bm25Embedder) EmbedStringsSparse(ctx context.Context, texts []string) ([]embedding.SparseVector, error) {
+	out := make([]embedding.SparseVector, len(texts))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i, text := range texts {
+		tf := termFreq(text)
+		e.observe(tf)
+		out[i] = e.score(tf)
+	}
+
+	return out, nil
+}
+
+// ScoreStringsSparse is the read-only, query-time counterpart to
+// EmbedStringsSparse: it scores texts against the corpus statistics
+// accumulated so far without folding them back in, so searching never
+// mutates the index it's searching. Terms never seen at indexing time have
+// no termID and are left out of the vector (weight 0), which is the correct
+// BM25 behaviour for an out-of-vocabulary query term.
+func (e *bm25Embedder) ScoreStringsSparse(ctx context.Context, texts []string) ([]embedding.SparseVector, error) {
+	out := make([]embedding.SparseVector, len(texts))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i, text := range texts {
+		out[i] = e.score(termFreq(text))
+	}
+
+	return out, nil
+}
+
+// observe folds one document's term frequencies into the corpus-wide
+// document-frequency / average-length statistics every score() call uses.
+func (e *bm25Embedder) observe(tf map[string]int) {
+	e.docCount++
+	length := 0
+	for term, freq := range tf {
+		e.df[term]++
+		length += freq
+		if _, ok := e.termIDs[term]; !ok {
+			e.termIDs[term] = uint32(len(e.termIDs))
+		}
+	}
+	e.totalLen += length
+}
+
+// score weights each term in tf by idf(term) * saturated-tf(term, doc
+// length), the standard Okapi BM25 term weight, against the corpus
+// statistics observe has accumulated so far.
+func (e *bm25Embedder) score(tf map[string]int) embedding.SparseVector {
+	length := 0
+	for _, freq := range tf {
+		length += freq
+	}
+	avgdl := 1.0
+	if e.docCount > 0 {
+		avgdl = float64(e.totalLen) / float64(e.docCount)
+	}
+	if avgdl <= 0 {
+		avgdl = 1.0
+	}
+
+	sv := embedding.SparseVector{
+		Indices: make([]uint32, 0, len(tf)),
+		Values:  make([]float32, 0, len(tf)),
+	}
+	for term, freq := range tf {
+		id, ok := e.termIDs[term]
+		if !ok {
+			continue
+		}
+		idf := idf(e.docCount, e.df[term])
+		tfNorm := float64(freq) * (e.k1 + 1) /
+			(float64(freq) + e.k1*(1-e.b+e.b*float64(length)/avgdl))
+
+		sv.Indices = append(sv.Indices, id)
+		sv.Values = append(sv.Values, float32(idf*tfNorm))
+	}
+	return sv
+}
+
+// idf is the BM25 (Robertson/Sparck-Jones) inverse document frequency,
+// smoothed with +0.5 and +1 inside the log so a term seen in every document
+// still gets a small positive weight rather than going to zero or negative.
+func idf(n, df int) float64 {
+	return math.Log((float64(n)-float64(df)+0.5)/(float64(df)+0.5) + 1)
+}
+
+func termFreq(text string) map[string]int {
+	tf := make(map[string]int)
+	for _, tok := range strings.Fields(strings.ToLower(text)) {
+		tf[tok]++
+	}
+	return tf
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package splade implements embedding.SparseEmbedder against an HTTP SPLADE
+// service: one POST per batch, {"texts": [...]} in, {"embeddings": [...]}
+// out, holding a same-length array of {token_id: weight} maps.
+package splade
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/embedding"
+)
+
+const defaultTimeout = 10 * time.Second
+
+type Config struct {
+	BaseURL string        // required, POST {texts: [...]} -> {embeddings: [{token_id: weight}, ...]}
+	APIKey  string        // optional, sent as "Authorization: Bearer <key>"
+	Timeout time.Duration // defaults to defaultTimeout
+}
+
+type spladeEmbedder struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewEmbedder builds an HTTP SPLADE embedding.SparseEmbedder.
+func NewEmbedder(cfg Config) (embedding.SparseEmbedder, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("[NewEmbedder] base_url not provided")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	return &spladeEmbedder{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+type spladeRequest struct {
+	Texts []string `json:"texts"`
+}
+
+type spladeResponse struct {
+	// Embeddings is one {token_id: weight} map per input text, token_id
+	// keyed as a string since it travels through JSON.
+	Embeddings []map[string]float32 `json:"embeddings"`
+}
+
+func (e *spladeEmbedder) EmbedStringsSparse(ctx context.Context, texts []string) ([]embedding.SparseVector, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(spladeRequest{Texts: texts})
+	if err != nil {
+		return nil, fmt.Errorf("[EmbedStringsSparse] marshal request failed, err=%w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.cfg.BaseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("[EmbedStringsSparse] build request failed, err=%w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.cfg.APIKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("[EmbedStringsSparse] do request failed, err=%w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("[EmbedStringsSparse] read response failed, err=%w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("[EmbedStringsSparse] unexpected status=%d, body=%s", resp.StatusCode, respBody)
+	}
+
+	var sr spladeResponse
+	if err := json.Unmarshal(respBody, &sr); err != nil {
+		return nil, fmt.Errorf("[EmbedStringsSparse] unmarshal response failed, err=%w", err)
+	}
+	if len(sr.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("[EmbedStringsSparse] expected %d embeddings, got %d", len(texts), len(sr.Embeddings))
+	}
+
+	out := make([]embedding.SparseVector, len(sr.Embeddings))
+	for i, m := range sr.Embeddings {
+		sv := embedding.SparseVector{Indices: make([]uint32, 0, len(m)), Values: make([]float32, 0, len(m))}
+		for tokenID, weight := range m {
+			id, err := strconv.ParseUint(tokenID, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("[EmbedStringsSparse] invalid token_id=%q, err=%w", tokenID, err)
+			}
+			sv.Indices = append(sv.Indices, uint32(id))
+			sv.Values = append(sv.Values, weight)
+		}
+		out[i] = sv
+	}
+
+	return out, nil
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dbstore
+
+import "time"
+
+// PromptTemplateVersion 提示词模板版本表
+type PromptTemplateVersion struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey;type:bigint(20) auto_increment"`
+	Name      string    `json:"name" gorm:"column:name;type:varchar(128);not null;uniqueIndex:idx_name_version"`
+	Version   string    `json:"version" gorm:"column:version;type:varchar(32);not null;uniqueIndex:idx_name_version"`
+	Messages  []byte    `json:"messages" gorm:"column:messages;type:longtext;not null"`
+	Active    bool      `json:"active" gorm:"column:active;not null;index:idx_name_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (PromptTemplateVersion) TableName() string {
+	return "prompt_template_versions"
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dbstore implements promptstore.TemplateStore on top of the
+// existing gorm.DB, versioning every publish as a row instead of a file on
+// disk. The first time a given template name has no rows, NewStore seeds it
+// as version "0.0.0" from the legacy resources/conf/prompt/*.json file that
+// template used to be read from once at startup, so upgrading needs no
+// manual migration.
+package dbstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"gorm.io/gorm"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/promptstore"
+)
+
+type Config struct {
+	DB *gorm.DB // required
+
+	// BootstrapPaths seeds a template's "0.0.0" version from a legacy
+	// jinja2 JSON file the first time that name has no rows in the table.
+	BootstrapPaths map[string]string // template name -> JSON file path
+}
+
+func NewStore(cfg Config) (promptstore.TemplateStore, error) {
+	if cfg.DB == nil {
+		return nil, fmt.Errorf("[NewStore] db not provided")
+	}
+
+	if err := cfg.DB.AutoMigrate(&PromptTemplateVersion{}); err != nil {
+		return nil, fmt.Errorf("[NewStore] migrate prompt_template_versions failed, err=%w", err)
+	}
+
+	s := &store{db: cfg.DB}
+	for name, path := range cfg.BootstrapPaths {
+		if err := s.bootstrap(name, path); err != nil {
+			return nil, fmt.Errorf("[NewStore] bootstrap template %q failed, err=%w", name, err)
+		}
+	}
+
+	return s, nil
+}
+
+type store struct {
+	db *gorm.DB
+}
+
+func (s *store) bootstrap(name, path string) error {
+	var count int64
+	if err := s.db.Model(&PromptTemplateVersion{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read bootstrap file failed, err=%w", err)
+	}
+
+	return s.db.Create(&PromptTemplateVersion{Name: name, Version: "0.0.0", Messages: b, Active: true}).Error
+}
+
+func (s *store) GetActive(ctx context.Context, name string) (*promptstore.Template, error) {
+	var row PromptTemplateVersion
+	if err := s.db.WithContext(ctx).Where("name = ? AND active = ?", name, true).Take(&row).Error; err != nil {
+		return nil, fmt.Errorf("[GetActive] no active version for template %q, err=%w", name, err)
+	}
+	return toTemplate(&row), nil
+}
+
+func (s *store) CreateVersion(ctx context.Context, name, version string, messages []byte) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&PromptTemplateVersion{}).
+			Where("name = ? AND active = ?", name, true).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Create(&PromptTemplateVersion{Name: name, Version: version, Messages: messages, Active: true}).Error
+	})
+}
+
+func (s *store) Rollback(ctx context.Context, name, version string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var row PromptTemplateVersion
+		if err := tx.Where("name = ? AND version = ?", name, version).Take(&row).Error; err != nil {
+			return fmt.Errorf("version %q not found for template %q, err=%w", version, name, err)
+		}
+
+		if err := tx.Model(&PromptTemplateVersion{}).
+			Where("name = ? AND active = ?", name, true).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&row).Update("active", true).Error
+	})
+}
+
+func (s *store) ListVersions(ctx context.Context, name string) ([]*promptstore.Template, error) {
+	var rows []*PromptTemplateVersion
+	if err := s.db.WithContext(ctx).Where("name = ?", name).Order("created_at DESC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("[ListVersions] query failed, err=%w", err)
+	}
+
+	out := make([]*promptstore.Template, len(rows))
+	for i, row := range rows {
+		out[i] = toTemplate(row)
+	}
+	return out, nil
+}
+
+// Watch is a no-op here: dbstore has no push channel of its own, so callers
+// that need sub-request freshness should call GetActive per request instead.
+func (s *store) Watch(ctx context.Context, name string, onChange func(*promptstore.Template)) (func(), error) {
+	return func() {}, nil
+}
+
+func toTemplate(row *PromptTemplateVersion) *promptstore.Template {
+	return &promptstore.Template{Name: row.Name, Version: row.Version, Messages: row.Messages}
+}
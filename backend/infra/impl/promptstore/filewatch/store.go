@@ -0,0 +1,174 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package filewatch implements promptstore.TemplateStore over plain JSON
+// files, for deployments that mount prompt templates from a
+// ConfigMap/secret volume instead of managing them through the database.
+// There's no version history beyond the file's current contents: Watch uses
+// fsnotify so an operator overwriting the mounted file is picked up without
+// a restart, the same outcome dbstore.Store gets from a new row.
+package filewatch
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/coze-dev/coze-studio/backend/infra/contract/promptstore"
+	"github.com/coze-dev/coze-studio/backend/pkg/logs"
+)
+
+type Config struct {
+	// Paths maps a template name to the JSON file it's mounted at.
+	Paths map[string]string
+}
+
+type store struct {
+	paths map[string]string
+
+	mu    sync.RWMutex
+	cache map[string]*promptstore.Template
+}
+
+// NewStore builds a filesystem-backed promptstore.TemplateStore and loads
+// every configured path once up front, so GetActive never has to touch disk
+// on the hot path.
+func NewStore(cfg Config) (promptstore.TemplateStore, error) {
+	s := &store{paths: cfg.Paths, cache: make(map[string]*promptstore.Template, len(cfg.Paths))}
+
+	for name := range cfg.Paths {
+		if _, err := s.reload(name); err != nil {
+			return nil, fmt.Errorf("[NewStore] load template %q failed, err=%w", name, err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *store) reload(name string) (*promptstore.Template, error) {
+	path, ok := s.paths[name]
+	if !ok {
+		return nil, fmt.Errorf("template %q not configured", name)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read template file failed, err=%w", err)
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	tpl := &promptstore.Template{Name: name, Version: fmt.Sprintf("file-%x", h.Sum64()), Messages: b}
+
+	s.mu.Lock()
+	s.cache[name] = tpl
+	s.mu.Unlock()
+
+	return tpl, nil
+}
+
+func (s *store) GetActive(ctx context.Context, name string) (*promptstore.Template, error) {
+	s.mu.RLock()
+	tpl, ok := s.cache[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("[GetActive] template %q not configured", name)
+	}
+	return tpl, nil
+}
+
+// CreateVersion overwrites the mounted file in place. There is no rollback
+// target once this returns, since the store keeps no history beyond the
+// current file contents.
+func (s *store) CreateVersion(ctx context.Context, name, version string, messages []byte) error {
+	path, ok := s.paths[name]
+	if !ok {
+		return fmt.Errorf("[CreateVersion] template %q not configured", name)
+	}
+	if err := os.WriteFile(path, messages, 0o644); err != nil {
+		return fmt.Errorf("[CreateVersion] write template file failed, err=%w", err)
+	}
+	_, err := s.reload(name)
+	return err
+}
+
+func (s *store) Rollback(ctx context.Context, name, version string) error {
+	return fmt.Errorf("[Rollback] filewatch store keeps no version history; mount the previous file instead")
+}
+
+func (s *store) ListVersions(ctx context.Context, name string) ([]*promptstore.Template, error) {
+	tpl, err := s.GetActive(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return []*promptstore.Template{tpl}, nil
+}
+
+// Watch follows the directory containing name's file (fsnotify can't watch
+// a bind-mounted single file across a ConfigMap atomic swap, which replaces
+// the containing symlink) and reloads whenever that specific path changes.
+func (s *store) Watch(ctx context.Context, name string, onChange func(*promptstore.Template)) (func(), error) {
+	path, ok := s.paths[name]
+	if !ok {
+		return nil, fmt.Errorf("[Watch] template %q not configured", name)
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("[Watch] create watcher failed, err=%w", err)
+	}
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		_ = w.Close()
+		return nil, fmt.Errorf("[Watch] watch dir failed, err=%w", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer w.Close()
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) != filepath.Clean(path) {
+					continue
+				}
+				if ev.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				tpl, err := s.reload(name)
+				if err != nil {
+					logs.Warnf("[Watch] reload template %q failed, err=%v", name, err)
+					continue
+				}
+				onChange(tpl)
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }, nil
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2025 coze-dev Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package advisor runs a small, table-driven set of static checks over a
+// generated OceanBase query before it reaches db.Raw(...).Scan, borrowing
+// the heuristic-rules idea from SQL-review tools: a single bad es.Request
+// shouldn't be able to table-scan project_search/resource_search just
+// because buildSQLQuery happily compiled it. New rules are added to Rules,
+// not by touching oceanbaseSearchClient.
+package advisor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coze-dev/coze-studio/backend/pkg/logs"
+)
+
+// Statement is the generated query Review checks, plus the facts about it
+// that oceanbaseSearchClient already has on hand while building the SQL
+// string and that would otherwise have to be re-derived by parsing it back
+// out of that string.
+type Statement struct {
+	Table string
+	SQL   string
+	Args  []interface{}
+
+	// LeadingWildcardColumns lists columns queried via a LIKE '%...' pattern
+	// that Table carries no FULLTEXT index on - this can't use any index
+	// and forces a full table scan.
+	LeadingWildcardColumns []string
+	// SortFields are the columns named in ORDER BY, in clause order.
+	SortFields []string
+	// IndexedColumns is the allow-list the unindexed-sort rule checks
+	// SortFields against. There's no full schema/DDL for project_search/
+	// resource_search in this tree, so this is only the columns
+	// oceanbaseSearchClient itself already knows are indexed (the primary
+	// key and any FULLTEXT columns), not a complete index inventory.
+	IndexedColumns map[string]bool
+	// Offset is the requested OFFSET, 0 if none was given.
+	Offset int
+	// MaxOffset is the configurable deep-pagination limit Offset is
+	// checked against. A non-positive MaxOffset disables the check.
+	MaxOffset int
+}
+
+// Violation is one rule firing against a Statement.
+type Violation struct {
+	RuleID  string
+	Message string
+	// Reject means Review returns an error instead of letting the
+	// statement run - the violation is serious enough that logging a
+	// warning and executing anyway isn't good enough.
+	Reject bool
+}
+
+// Rule is one static-analysis check. It returns the violations it found; a
+// nil/empty slice means the rule didn't fire.
+type Rule func(stmt *Statement) []Violation
+
+// Rules is the table-driven rule set Review runs, in order. Add new rules
+// here rather than changing Review's signature or callers.
+var Rules = []Rule{
+	ruleLeadingWildcardLike,
+	ruleDeepOffset,
+	ruleUnindexedSort,
+}
+
+// Review runs every rule in Rules against stmt, logging each violation via
+// logs.CtxWarnf tagged with the rule ID that fired. If any violation is
+// Reject, Review returns an error built from the first one instead of
+// letting the caller execute stmt at all.
+func Review(ctx context.Context, stmt *Statement) error {
+	var reject *Violation
+
+	for _, rule := range Rules {
+		for _, v := range rule(stmt) {
+			v := v
+			logs.CtxWarnf(ctx, "[sql-advisor] rule=%s table=%s msg=%s", v.RuleID, stmt.Table, v.Message)
+			if v.Reject && reject == nil {
+				reject = &v
+			}
+		}
+	}
+
+	if reject != nil {
+		return fmt.Errorf("[sql-advisor] rule %q rejected query on %s: %s", reject.RuleID, stmt.Table, reject.Message)
+	}
+	return nil
+}
+
+// ruleLeadingWildcardLike rejects LIKE '%...' queries against columns with
+// no FULLTEXT index: they can't use any index and scan the whole table, and
+// unlike a deep OFFSET there's no cheaper equivalent query to suggest instead.
+func ruleLeadingWildcardLike(stmt *Statement) []Violation {
+	var out []Violation
+	for _, col := range stmt.LeadingWildcardColumns {
+		out = append(out, Violation{
+			RuleID:  "leading-wildcard-like",
+			Message: fmt.Sprintf("LIKE '%%...' on column %q has no FULLTEXT index and will scan %s", col, stmt.Table),
+			Reject:  true,
+		})
+	}
+	return out
+}
+
+// ruleDeepOffset rejects OFFSETs beyond MaxOffset. OceanBase (like MySQL)
+// still has to read and discard every row up to OFFSET, so a deep page
+// request is as expensive as scanning the whole result set; the caller
+// should paginate by keyset (WHERE sort_key > last_seen_value) instead,
+// which this tree's es.Request has no field for yet.
+func ruleDeepOffset(stmt *Statement) []Violation {
+	if stmt.MaxOffset > 0 && stmt.Offset > stmt.MaxOffset {
+		return []Violation{{
+			RuleID: "deep-offset",
+			Message: fmt.Sprintf("OFFSET %d exceeds the deep-pagination limit %d; paginate by keyset on the last sort key instead",
+				stmt.Offset, stmt.MaxOffset),
+			Reject: true,
+		}}
+	}
+	return nil
+}
+
+// ruleUnindexedSort warns (doesn't reject) on an ORDER BY column outside
+// IndexedColumns: it forces a filesort instead of walking an index in
+// order, which is slow but not as unbounded as a full table scan, so it's
+// not worth failing the request over.
+func ruleUnindexedSort(stmt *Statement) []Violation {
+	if len(stmt.IndexedColumns) == 0 {
+		return nil
+	}
+
+	var out []Violation
+	for _, f := range stmt.SortFields {
+		if !stmt.IndexedColumns[f] {
+			out = append(out, Violation{
+				RuleID:  "unindexed-sort",
+				Message: fmt.Sprintf("ORDER BY %q is not a known indexed column on %s and may force a filesort", f, stmt.Table),
+			})
+		}
+	}
+	return out
+}
@@ -20,15 +20,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/coze-dev/coze-studio/backend/infra/contract/es"
+	"github.com/coze-dev/coze-studio/backend/infra/impl/search/oceanbase/advisor"
 	"github.com/coze-dev/coze-studio/backend/pkg/logs"
 )
 
+// defaultMaxOffset is the deep-pagination cap the advisor enforces when
+// OCEANBASE_MAX_OFFSET isn't set.
+const defaultMaxOffset = 10000
+
 type oceanbaseSearchClient struct {
 	db *gorm.DB
 }
@@ -58,19 +68,31 @@ func (o *oceanbaseSearchClient) Search(ctx context.Context, index string, req *e
 	}
 
 	// 构建SQL查询
-	sqlQuery, args := o.buildSQLQuery(tableName, req)
+	sqlQuery, args, err := o.buildSQLQuery(ctx, tableName, req)
+	if err != nil {
+		return nil, fmt.Errorf("oceanbase build query failed: %w", err)
+	}
 
 	logs.CtxDebugf(ctx, "[OceanBase Search] SQL: %s, Args: %v", sqlQuery, args)
 
 	// 执行查询
 	var results []map[string]interface{}
-	if err := o.db.Raw(sqlQuery, args...).Scan(&results).Error; err != nil {
+	if err := o.db.WithContext(ctx).Raw(sqlQuery, args...).Scan(&results).Error; err != nil {
 		return nil, fmt.Errorf("oceanbase search failed: %w", err)
 	}
 
 	// 转换为ES响应格式
 	hits := make([]es.Hit, 0, len(results))
 	for _, result := range results {
+		// _score是MATCH() AGAINST()查询附带的相关性分数，不属于文档本身
+		var score *float64
+		if raw, ok := result["_score"]; ok {
+			delete(result, "_score")
+			if f, ok := toFloat64(raw); ok {
+				score = &f
+			}
+		}
+
 		// 将结果转换为JSON
 		source, err := json.Marshal(result)
 		if err != nil {
@@ -92,7 +114,7 @@ func (o *oceanbaseSearchClient) Search(ctx context.Context, index string, req *e
 
 		hits = append(hits, es.Hit{
 			Id_:     &id,
-			Score_:  nil, // OceanBase不支持评分
+			Score_:  score,
 			Source_: source,
 		})
 	}
@@ -107,18 +129,47 @@ func (o *oceanbaseSearchClient) Search(ctx context.Context, index string, req *e
 	}, nil
 }
 
-func (o *oceanbaseSearchClient) buildSQLQuery(tableName string, req *es.Request) (string, []interface{}) {
-	var args []interface{}
+// fulltextMatch records the single FULLTEXT MATCH()...AGAINST() expression
+// driving a query's relevance score, if any: the first QueryTypeMatch query
+// against a column carrying a FULLTEXT index wins, since OceanBase can only
+// project one such expression into a _score column per statement. Any
+// further match terms still filter via the same MATCH() expression in the
+// WHERE clause, they just don't also drive the score.
+type fulltextMatch struct {
+	column string
+	term   string
+}
 
-	// 基础查询
-	sql := fmt.Sprintf("SELECT * FROM %s WHERE 1=1", tableName)
+func (o *oceanbaseSearchClient) buildSQLQuery(ctx context.Context, tableName string, req *es.Request) (string, []interface{}, error) {
+	fulltextCols, err := o.fulltextColumns(ctx, tableName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	where := "WHERE 1=1"
+	var args []interface{}
+	var match *fulltextMatch
+	stats := &queryStats{}
 
 	// 处理查询条件
 	if req.Query != nil && req.Query.Bool != nil {
-		sql, args = o.processBoolQuery(sql, args, req.Query.Bool)
+		var err error
+		where, args, match, err = o.processBoolQuery(where, args, req.Query.Bool, fulltextCols, match, stats)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	selectCols := "*"
+	if match != nil {
+		selectCols = fmt.Sprintf("*, MATCH(%s) AGAINST (? IN NATURAL LANGUAGE MODE) AS _score", match.column)
+		args = append([]interface{}{match.term}, args...)
 	}
 
+	sql := fmt.Sprintf("SELECT %s FROM %s %s", selectCols, tableName, where)
+
 	// 处理排序
+	var sortFields []string
 	if len(req.Sort) > 0 {
 		var sortClauses []string
 		for _, sort := range req.Sort {
@@ -127,8 +178,12 @@ func (o *oceanbaseSearchClient) buildSQLQuery(tableName string, req *es.Request)
 				order = "DESC"
 			}
 			sortClauses = append(sortClauses, fmt.Sprintf("%s %s", sort.Field, order))
+			sortFields = append(sortFields, sort.Field)
 		}
 		sql += " ORDER BY " + strings.Join(sortClauses, ", ")
+	} else if match != nil {
+		// 没有显式排序时，按相关性分数降序，与Elasticsearch的match查询默认行为一致
+		sql += " ORDER BY _score DESC"
 	}
 
 	// 处理分页
@@ -136,22 +191,71 @@ func (o *oceanbaseSearchClient) buildSQLQuery(tableName string, req *es.Request)
 		sql += fmt.Sprintf(" LIMIT %d", *req.Size)
 	}
 
+	offset := 0
 	if req.From != nil {
-		sql += fmt.Sprintf(" OFFSET %d", *req.From)
+		offset = *req.From
+		sql += fmt.Sprintf(" OFFSET %d", offset)
+	}
+
+	indexedCols := make(map[string]bool, len(fulltextCols)+1)
+	for col := range fulltextCols {
+		indexedCols[col] = true
+	}
+	indexedCols["id"] = true
+
+	stmt := &advisor.Statement{
+		Table:                  tableName,
+		SQL:                    sql,
+		Args:                   args,
+		LeadingWildcardColumns: stats.leadingWildcardColumns,
+		SortFields:             sortFields,
+		IndexedColumns:         indexedCols,
+		Offset:                 offset,
+		MaxOffset:              o.maxOffset(),
+	}
+	if err := advisor.Review(ctx, stmt); err != nil {
+		return "", nil, err
+	}
+
+	return sql, args, nil
+}
+
+// maxOffset is the deep-pagination limit the advisor enforces, read from
+// OCEANBASE_MAX_OFFSET once per query so it can be tuned without a restart
+// of... well, it still needs a restart, but at least not a code change.
+func (o *oceanbaseSearchClient) maxOffset() int {
+	if v := os.Getenv("OCEANBASE_MAX_OFFSET"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
 	}
+	return defaultMaxOffset
+}
 
-	return sql, args
+// queryStats accumulates facts buildSQLQuery hands to the advisor package,
+// gathered while walking the query tree instead of re-parsing them back out
+// of the generated SQL string.
+type queryStats struct {
+	leadingWildcardColumns []string
 }
 
-func (o *oceanbaseSearchClient) processBoolQuery(sql string, args []interface{}, boolQuery *es.BoolQuery) (string, []interface{}) {
+func (o *oceanbaseSearchClient) processBoolQuery(sql string, args []interface{}, boolQuery *es.BoolQuery, fulltextCols map[string]bool, match *fulltextMatch, stats *queryStats) (string, []interface{}, *fulltextMatch, error) {
+	var err error
+
 	// 处理Must条件
 	for _, query := range boolQuery.Must {
-		sql, args = o.processQuery(sql, args, &query, "AND")
+		sql, args, match, err = o.processQuery(sql, args, &query, "AND", fulltextCols, match, stats)
+		if err != nil {
+			return sql, args, match, err
+		}
 	}
 
 	// 处理Filter条件
 	for _, query := range boolQuery.Filter {
-		sql, args = o.processQuery(sql, args, &query, "AND")
+		sql, args, match, err = o.processQuery(sql, args, &query, "AND", fulltextCols, match, stats)
+		if err != nil {
+			return sql, args, match, err
+		}
 	}
 
 	// 处理Should条件
@@ -160,7 +264,12 @@ func (o *oceanbaseSearchClient) processBoolQuery(sql string, args []interface{},
 		var shouldArgs []interface{}
 
 		for _, query := range boolQuery.Should {
-			clause, clauseArgs := o.processQuery("", shouldArgs, &query, "")
+			var clause string
+			var clauseArgs []interface{}
+			clause, clauseArgs, match, err = o.processQuery("", nil, &query, "", fulltextCols, match, stats)
+			if err != nil {
+				return sql, args, match, err
+			}
 			if clause != "" {
 				shouldClauses = append(shouldClauses, strings.TrimPrefix(clause, "AND "))
 				shouldArgs = append(shouldArgs, clauseArgs...)
@@ -175,60 +284,251 @@ func (o *oceanbaseSearchClient) processBoolQuery(sql string, args []interface{},
 
 	// 处理MustNot条件
 	for _, query := range boolQuery.MustNot {
-		sql, args = o.processQuery(sql, args, &query, "AND NOT")
+		sql, args, match, err = o.processQuery(sql, args, &query, "AND NOT", fulltextCols, match, stats)
+		if err != nil {
+			return sql, args, match, err
+		}
+	}
+
+	return sql, args, match, nil
+}
+
+// columnNameRe is the allow-list processQuery checks every identifier it
+// interpolates into raw SQL against (query.KV.Key, Range.Field). Sort
+// fields, and the document keys Create, Update and the bulk indexer write
+// as column names, are interpolated without this check. There's no schema/DDL
+// for project_search/resource_search anywhere in this tree to check real
+// column names against - their documents are free-form maps written by
+// Create/Update - so instead of an enumerated list this allow-lists the
+// *shape* of a safe identifier and rejects anything else outright, which is
+// what actually closes the injection hole (quotes, backticks, whitespace,
+// statement separators) rather than just relocating it.
+var columnNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func quoteColumn(col string) (string, error) {
+	if !columnNameRe.MatchString(col) {
+		return "", fmt.Errorf("invalid column name %q", col)
 	}
+	return "`" + col + "`", nil
+}
 
-	return sql, args
+// escapeLike escapes LIKE's own wildcard characters out of a value before it
+// is used as the LIKE pattern for QueryTypeTerm (as is) or QueryTypePrefix
+// (with a trailing %), so the searched-for value can't smuggle in its own
+// wildcard.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return r.Replace(s)
 }
 
-func (o *oceanbaseSearchClient) processQuery(sql string, args []interface{}, query *es.Query, operator string) (string, []interface{}) {
+func (o *oceanbaseSearchClient) processQuery(sql string, args []interface{}, query *es.Query, operator string, fulltextCols map[string]bool, match *fulltextMatch, stats *queryStats) (string, []interface{}, *fulltextMatch, error) {
+	// 嵌套的bool查询，递归处理后整体加括号
+	if query.Bool != nil {
+		nestedSQL, nestedArgs, m, err := o.processBoolQuery("", nil, query.Bool, fulltextCols, match, stats)
+		if err != nil {
+			return sql, args, match, err
+		}
+		match = m
+
+		inner := strings.TrimSpace(nestedSQL)
+		if inner == "" {
+			return sql, args, match, nil
+		}
+
+		clause := fmt.Sprintf("%s (%s)", operator, inner)
+		if sql == "" {
+			clause = fmt.Sprintf("(%s)", inner)
+		}
+		return sql + " " + clause, append(args, nestedArgs...), match, nil
+	}
+
 	switch query.Type {
 	case es.QueryTypeEqual:
-		clause := fmt.Sprintf("%s %s = ?", operator, query.KV.Key)
+		col, err := quoteColumn(query.KV.Key)
+		if err != nil {
+			return sql, args, match, err
+		}
+		clause := fmt.Sprintf("%s %s = ?", operator, col)
 		if sql == "" {
-			clause = fmt.Sprintf("%s = ?", query.KV.Key)
+			clause = fmt.Sprintf("%s = ?", col)
 		}
-		return sql + " " + clause, append(args, query.KV.Value)
+		return sql + " " + clause, append(args, query.KV.Value), match, nil
 
 	case es.QueryTypeMatch:
-		// 使用LIKE进行模糊匹配
-		clause := fmt.Sprintf("%s %s LIKE ?", operator, query.KV.Key)
+		col, err := quoteColumn(query.KV.Key)
+		if err != nil {
+			return sql, args, match, err
+		}
+		term := fmt.Sprint(query.KV.Value)
+
+		if fulltextCols[query.KV.Key] {
+			// 该列有FULLTEXT索引，使用自然语言模式的MATCH()...AGAINST()保留相关性排序
+			clause := fmt.Sprintf("%s MATCH(%s) AGAINST (? IN NATURAL LANGUAGE MODE)", operator, col)
+			if sql == "" {
+				clause = fmt.Sprintf("MATCH(%s) AGAINST (? IN NATURAL LANGUAGE MODE)", col)
+			}
+			if match == nil {
+				match = &fulltextMatch{column: query.KV.Key, term: term}
+			}
+			return sql + " " + clause, append(args, term), match, nil
+		}
+
+		// 该列没有FULLTEXT索引，退化为LIKE模糊匹配
+		clause := fmt.Sprintf("%s %s LIKE ?", operator, col)
 		if sql == "" {
-			clause = fmt.Sprintf("%s LIKE ?", query.KV.Key)
+			clause = fmt.Sprintf("%s LIKE ?", col)
 		}
-		return sql + " " + clause, append(args, "%"+fmt.Sprint(query.KV.Value)+"%")
+		stats.leadingWildcardColumns = append(stats.leadingWildcardColumns, query.KV.Key)
+		return sql + " " + clause, append(args, "%"+term+"%"), match, nil
 
 	case es.QueryTypeContains:
 		// 使用LIKE进行包含匹配
-		clause := fmt.Sprintf("%s %s LIKE ?", operator, query.KV.Key)
+		col, err := quoteColumn(query.KV.Key)
+		if err != nil {
+			return sql, args, match, err
+		}
+		clause := fmt.Sprintf("%s %s LIKE ?", operator, col)
 		if sql == "" {
-			clause = fmt.Sprintf("%s LIKE ?", query.KV.Key)
+			clause = fmt.Sprintf("%s LIKE ?", col)
+		}
+		if !fulltextCols[query.KV.Key] {
+			stats.leadingWildcardColumns = append(stats.leadingWildcardColumns, query.KV.Key)
 		}
-		return sql + " " + clause, append(args, "%"+fmt.Sprint(query.KV.Value)+"%")
+		return sql + " " + clause, append(args, "%"+fmt.Sprint(query.KV.Value)+"%"), match, nil
 
 	case es.QueryTypeIn:
+		col, err := quoteColumn(query.KV.Key)
+		if err != nil {
+			return sql, args, match, err
+		}
 		// 处理IN查询
 		if values, ok := query.KV.Value.([]interface{}); ok {
 			placeholders := make([]string, len(values))
 			for i := range values {
 				placeholders[i] = "?"
 			}
-			clause := fmt.Sprintf("%s %s IN (%s)", operator, query.KV.Key, strings.Join(placeholders, ","))
+			clause := fmt.Sprintf("%s %s IN (%s)", operator, col, strings.Join(placeholders, ","))
 			if sql == "" {
-				clause = fmt.Sprintf("%s IN (%s)", query.KV.Key, strings.Join(placeholders, ","))
+				clause = fmt.Sprintf("%s IN (%s)", col, strings.Join(placeholders, ","))
 			}
-			return sql + " " + clause, append(args, values...)
+			return sql + " " + clause, append(args, values...), match, nil
 		}
 
 	case es.QueryTypeNotExists:
-		clause := fmt.Sprintf("%s %s IS NULL", operator, query.KV.Key)
+		col, err := quoteColumn(query.KV.Key)
+		if err != nil {
+			return sql, args, match, err
+		}
+		clause := fmt.Sprintf("%s %s IS NULL", operator, col)
+		if sql == "" {
+			clause = fmt.Sprintf("%s IS NULL", col)
+		}
+		return sql + " " + clause, args, match, nil
+
+	case es.QueryTypeExists:
+		col, err := quoteColumn(query.KV.Key)
+		if err != nil {
+			return sql, args, match, err
+		}
+		clause := fmt.Sprintf("%s %s IS NOT NULL", operator, col)
+		if sql == "" {
+			clause = fmt.Sprintf("%s IS NOT NULL", col)
+		}
+		return sql + " " + clause, args, match, nil
+
+	case es.QueryTypeTerm, es.QueryTypePrefix:
+		col, err := quoteColumn(query.KV.Key)
+		if err != nil {
+			return sql, args, match, err
+		}
+		pattern := escapeLike(fmt.Sprint(query.KV.Value))
+		if query.Type == es.QueryTypePrefix {
+			pattern += "%"
+		}
+		clause := fmt.Sprintf(`%s %s LIKE ? ESCAPE '\\'`, operator, col)
+		if sql == "" {
+			clause = fmt.Sprintf(`%s LIKE ? ESCAPE '\\'`, col)
+		}
+		return sql + " " + clause, append(args, pattern), match, nil
+
+	case es.QueryTypeRange:
+		if query.Range == nil {
+			return sql, args, match, fmt.Errorf("range query missing Range")
+		}
+		col, err := quoteColumn(query.Range.Field)
+		if err != nil {
+			return sql, args, match, err
+		}
+
+		var bounds []string
+		var boundArgs []interface{}
+		if query.Range.Gt != nil {
+			bounds = append(bounds, fmt.Sprintf("%s > ?", col))
+			boundArgs = append(boundArgs, query.Range.Gt)
+		}
+		if query.Range.Gte != nil {
+			bounds = append(bounds, fmt.Sprintf("%s >= ?", col))
+			boundArgs = append(boundArgs, query.Range.Gte)
+		}
+		if query.Range.Lt != nil {
+			bounds = append(bounds, fmt.Sprintf("%s < ?", col))
+			boundArgs = append(boundArgs, query.Range.Lt)
+		}
+		if query.Range.Lte != nil {
+			bounds = append(bounds, fmt.Sprintf("%s <= ?", col))
+			boundArgs = append(boundArgs, query.Range.Lte)
+		}
+		if len(bounds) == 0 {
+			return sql, args, match, nil
+		}
+
+		combined := "(" + strings.Join(bounds, " AND ") + ")"
+		clause := fmt.Sprintf("%s %s", operator, combined)
 		if sql == "" {
-			clause = fmt.Sprintf("%s IS NULL", query.KV.Key)
+			clause = combined
 		}
-		return sql + " " + clause, args
+		return sql + " " + clause, append(args, boundArgs...), match, nil
+	}
+
+	return sql, args, match, nil
+}
+
+// fulltextColumns returns the set of tableName's columns carrying a
+// FULLTEXT index, so processQuery knows whether a QueryTypeMatch query can
+// compile to MATCH()...AGAINST() or has to fall back to LIKE.
+func (o *oceanbaseSearchClient) fulltextColumns(ctx context.Context, tableName string) (map[string]bool, error) {
+	var cols []string
+	sql := `SELECT DISTINCT COLUMN_NAME FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_TYPE = 'FULLTEXT'`
+	if err := o.db.WithContext(ctx).Raw(sql, tableName).Pluck("COLUMN_NAME", &cols).Error; err != nil {
+		return nil, fmt.Errorf("query fulltext indexes for %s failed, err=%w", tableName, err)
 	}
 
-	return sql, args
+	set := make(map[string]bool, len(cols))
+	for _, col := range cols {
+		set[col] = true
+	}
+	return set, nil
+}
+
+// toFloat64 normalizes the numeric types a raw SQL scan into map[string]any
+// can hand back for a computed column like _score, which varies by driver
+// (float64, float32, or a decimal string).
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case []byte:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
 }
 
 func (o *oceanbaseSearchClient) Create(ctx context.Context, index string, id string, doc interface{}) error {
@@ -342,10 +642,42 @@ func (o *oceanbaseSearchClient) Exists(ctx context.Context, index string) (bool,
 }
 
 func (o *oceanbaseSearchClient) CreateIndex(ctx context.Context, index string, properties map[string]any) error {
-	// OceanBase的表结构已经在初始化时创建，这里不需要做任何事情
+	// OceanBase的表结构已经在初始化时创建，这里只需要为properties中"text"类型的
+	// 字段补充FULLTEXT索引，使查询路径里的MATCH()...AGAINST()能够命中
+	var tableName string
+	switch index {
+	case "project_search", "project_draft":
+		tableName = "project_search"
+	case "resource_search":
+		tableName = "resource_search"
+	default:
+		return nil
+	}
+
+	existing, err := o.fulltextColumns(ctx, tableName)
+	if err != nil {
+		return err
+	}
+
+	for field, prop := range properties {
+		propMap, ok := prop.(map[string]string)
+		if !ok || propMap["type"] != "text" || existing[field] {
+			continue
+		}
+
+		sql := fmt.Sprintf("ALTER TABLE %s ADD FULLTEXT INDEX %s (%s)", tableName, fulltextIndexName(field), field)
+		if err := o.db.WithContext(ctx).Exec(sql).Error; err != nil {
+			return fmt.Errorf("add fulltext index for %s.%s failed, err=%w", tableName, field, err)
+		}
+	}
+
 	return nil
 }
 
+func fulltextIndexName(column string) string {
+	return "ft_" + column
+}
+
 func (o *oceanbaseSearchClient) DeleteIndex(ctx context.Context, index string) error {
 	// 删除表
 	sql := fmt.Sprintf("DROP TABLE IF EXISTS %s", index)
@@ -356,11 +688,34 @@ func (o *oceanbaseSearchClient) Types() es.Types {
 	return &oceanbaseTypes{}
 }
 
+const (
+	defaultBulkBatchSize     = 200
+	defaultBulkFlushInterval = 2 * time.Second
+)
+
 func (o *oceanbaseSearchClient) NewBulkIndexer(index string) (es.BulkIndexer, error) {
-	return &oceanbaseBulkIndexer{
-		db:    o.db,
-		index: index,
-	}, nil
+	batchSize := defaultBulkBatchSize
+	if n, err := strconv.Atoi(os.Getenv("OCEANBASE_BULK_BATCH_SIZE")); err == nil && n > 0 {
+		batchSize = n
+	}
+	flushInterval := defaultBulkFlushInterval
+	if ms, err := strconv.Atoi(os.Getenv("OCEANBASE_BULK_FLUSH_INTERVAL_MS")); err == nil && ms > 0 {
+		flushInterval = time.Duration(ms) * time.Millisecond
+	}
+
+	b := &oceanbaseBulkIndexer{
+		db:            o.db,
+		index:         index,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		flushNow:      make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+	}
+
+	b.wg.Add(1)
+	go b.run()
+
+	return b, nil
 }
 
 type oceanbaseTypes struct{}
@@ -377,47 +732,360 @@ func (t *oceanbaseTypes) NewUnsignedLongNumberProperty() any {
 	return map[string]string{"type": "unsigned_long"}
 }
 
+// bulkItem pairs one es.BulkIndexerItem with its already-decoded document
+// body, so flush doesn't have to re-read item.Body (an io.ReadSeeker that
+// may not tolerate being read twice from separate goroutines) once it has
+// been buffered.
+type bulkItem struct {
+	item es.BulkIndexerItem
+	doc  map[string]interface{}
+}
+
+// oceanbaseBulkIndexer buffers Add calls in memory, grouped by action and
+// flushed together as multi-row statements inside one transaction, instead
+// of the one-round-trip-per-item behavior client.Create/Update/Delete give
+// when called directly. A background goroutine flushes on whichever comes
+// first: the buffer reaching batchSize, or flushInterval elapsing.
 type oceanbaseBulkIndexer struct {
 	db    *gorm.DB
 	index string
+
+	batchSize     int
+	flushInterval time.Duration
+
+	mu     sync.Mutex
+	buffer []bulkItem
+	closed bool
+
+	flushNow chan struct{}
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func (b *oceanbaseBulkIndexer) run() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.flush(context.Background())
+		case <-b.flushNow:
+			b.flush(context.Background())
+		case <-b.stop:
+			return
+		}
+	}
 }
 
 func (b *oceanbaseBulkIndexer) Add(ctx context.Context, item es.BulkIndexerItem) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return fmt.Errorf("bulk indexer closed")
+	}
+	b.mu.Unlock()
+
+	bi := bulkItem{item: item}
+
 	switch item.Action {
-	case "index", "create":
-		// 读取文档内容
-		item.Body.Seek(0, 0)
-		var doc interface{}
-		if err := json.NewDecoder(item.Body).Decode(&doc); err != nil {
-			return err
+	case "index", "create", "update":
+		if item.Body != nil {
+			// 读取文档内容
+			item.Body.Seek(0, 0)
+			if err := json.NewDecoder(item.Body).Decode(&bi.doc); err != nil {
+				return fmt.Errorf("decode bulk item body failed, err=%w", err)
+			}
 		}
+	case "delete":
+		// 无需文档体
+	default:
+		return fmt.Errorf("unsupported bulk action: %s", item.Action)
+	}
 
-		// 使用Create方法插入文档
-		client := &oceanbaseSearchClient{db: b.db}
-		return client.Create(ctx, b.index, item.DocumentID, doc)
+	b.mu.Lock()
+	b.buffer = append(b.buffer, bi)
+	full := len(b.buffer) >= b.batchSize
+	b.mu.Unlock()
 
-	case "update":
-		// 读取文档内容
-		item.Body.Seek(0, 0)
-		var doc interface{}
-		if err := json.NewDecoder(item.Body).Decode(&doc); err != nil {
-			return err
+	if full {
+		select {
+		case b.flushNow <- struct{}{}:
+		default:
+			// 已经有一次flush在排队，不需要再触发
 		}
+	}
+
+	return nil
+}
+
+// tableBatch groups one flush's buffered items for a single table by the
+// multi-row statement they compile to.
+type tableBatch struct {
+	upserts []bulkItem // action index/create -> INSERT ... ON DUPLICATE KEY UPDATE
+	updates []bulkItem // action update -> CASE WHEN based multi-row UPDATE
+	deletes []bulkItem // action delete -> DELETE ... WHERE id IN (...)
+}
 
-		// 使用Update方法更新文档
-		client := &oceanbaseSearchClient{db: b.db}
-		return client.Update(ctx, b.index, item.DocumentID, doc)
+// flush drains the current buffer and commits it as one transaction per
+// target table, reporting per-item outcomes through OnSuccess/OnFailure.
+func (b *oceanbaseBulkIndexer) flush(ctx context.Context) error {
+	b.mu.Lock()
+	batch := b.buffer
+	b.buffer = nil
+	b.mu.Unlock()
 
-	case "delete":
-		// 使用Delete方法删除文档
-		client := &oceanbaseSearchClient{db: b.db}
-		return client.Delete(ctx, b.index, item.DocumentID)
+	if len(batch) == 0 {
+		return nil
 	}
 
-	return nil
+	byTable := make(map[string]*tableBatch)
+	for _, bi := range batch {
+		idx := bi.item.Index
+		if idx == "" {
+			idx = b.index
+		}
+
+		tableName, ok := tableNameForIndex(idx)
+		if !ok {
+			b.fail(ctx, bi, fmt.Errorf("unknown index: %s", idx))
+			continue
+		}
+
+		tb, ok := byTable[tableName]
+		if !ok {
+			tb = &tableBatch{}
+			byTable[tableName] = tb
+		}
+
+		switch bi.item.Action {
+		case "index", "create":
+			tb.upserts = append(tb.upserts, bi)
+		case "update":
+			tb.updates = append(tb.updates, bi)
+		case "delete":
+			tb.deletes = append(tb.deletes, bi)
+		}
+	}
+
+	var flushErr error
+	for tableName, tb := range byTable {
+		items := append(append(append([]bulkItem{}, tb.upserts...), tb.updates...), tb.deletes...)
+
+		err := b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if len(tb.upserts) > 0 {
+				if err := execBulkUpsert(tx, tableName, tb.upserts); err != nil {
+					return fmt.Errorf("bulk upsert into %s failed, err=%w", tableName, err)
+				}
+			}
+			if len(tb.updates) > 0 {
+				if err := execBulkUpdate(tx, tableName, tb.updates); err != nil {
+					return fmt.Errorf("bulk update %s failed, err=%w", tableName, err)
+				}
+			}
+			if len(tb.deletes) > 0 {
+				if err := execBulkDelete(tx, tableName, tb.deletes); err != nil {
+					return fmt.Errorf("bulk delete from %s failed, err=%w", tableName, err)
+				}
+			}
+			return nil
+		})
+
+		if err != nil {
+			flushErr = err
+			for _, bi := range items {
+				b.fail(ctx, bi, err)
+			}
+			continue
+		}
+
+		for _, bi := range items {
+			b.succeed(ctx, bi)
+		}
+	}
+
+	return flushErr
+}
+
+func (b *oceanbaseBulkIndexer) succeed(ctx context.Context, bi bulkItem) {
+	if bi.item.OnSuccess != nil {
+		bi.item.OnSuccess(ctx, bi.item, es.BulkIndexerResponseItem{DocumentID: bi.item.DocumentID})
+	}
+}
+
+func (b *oceanbaseBulkIndexer) fail(ctx context.Context, bi bulkItem, err error) {
+	if bi.item.OnFailure != nil {
+		bi.item.OnFailure(ctx, bi.item, es.BulkIndexerResponseItem{DocumentID: bi.item.DocumentID}, err)
+	}
 }
 
+// Close stops the background flush goroutine and drains whatever remains
+// in the buffer before returning.
 func (b *oceanbaseBulkIndexer) Close(ctx context.Context) error {
-	// 批量操作完成，不需要特殊处理
-	return nil
+	b.mu.Lock()
+	alreadyClosed := b.closed
+	b.closed = true
+	b.mu.Unlock()
+
+	if alreadyClosed {
+		return nil
+	}
+
+	close(b.stop)
+	b.wg.Wait()
+
+	return b.flush(ctx)
+}
+
+// tableNameForIndex resolves a logical ES index name to the backing
+// OceanBase table, the same mapping Search/Create/Update/Delete use.
+func tableNameForIndex(index string) (string, bool) {
+	switch index {
+	case "project_search", "project_draft":
+		return "project_search", true
+	case "resource_search":
+		return "resource_search", true
+	default:
+		return "", false
+	}
+}
+
+// execBulkUpsert compiles items into one multi-row
+// INSERT ... ON DUPLICATE KEY UPDATE, over the union of columns across all
+// of them so every row can share a single VALUES list shape.
+func execBulkUpsert(tx *gorm.DB, tableName string, items []bulkItem) error {
+	columns := unionColumns(items)
+	if len(columns) == 0 {
+		return nil
+	}
+
+	rowPlaceholder := "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")"
+	rowPlaceholders := make([]string, 0, len(items))
+	args := make([]interface{}, 0, len(items)*len(columns))
+
+	for _, bi := range items {
+		doc := bi.doc
+		if doc == nil {
+			doc = map[string]interface{}{}
+		}
+		if bi.item.DocumentID != "" {
+			doc["id"] = bi.item.DocumentID
+		}
+
+		rowPlaceholders = append(rowPlaceholders, rowPlaceholder)
+		for _, col := range columns {
+			args = append(args, doc[col])
+		}
+	}
+
+	updates := make([]string, len(columns))
+	for i, col := range columns {
+		updates[i] = fmt.Sprintf("%s=VALUES(%s)", col, col)
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON DUPLICATE KEY UPDATE %s",
+		tableName, strings.Join(columns, ","), strings.Join(rowPlaceholders, ","), strings.Join(updates, ","))
+
+	return tx.Exec(sql, args...).Error
+}
+
+// execBulkUpdate compiles items into one multi-row UPDATE, using a
+// CASE id WHEN ? THEN ? ... ELSE col END expression per column so rows that
+// don't set a given column keep its existing value.
+func execBulkUpdate(tx *gorm.DB, tableName string, items []bulkItem) error {
+	rows := make([]bulkItem, 0, len(items))
+	for _, bi := range items {
+		if bi.item.DocumentID != "" {
+			rows = append(rows, bi)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	colSet := make(map[string]struct{})
+	for _, bi := range rows {
+		for col := range bi.doc {
+			colSet[col] = struct{}{}
+		}
+	}
+	columns := make([]string, 0, len(colSet))
+	for col := range colSet {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	if len(columns) == 0 {
+		return nil
+	}
+
+	sets := make([]string, 0, len(columns))
+	var args []interface{}
+	for _, col := range columns {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%s = CASE id", col))
+		for _, bi := range rows {
+			if v, ok := bi.doc[col]; ok {
+				sb.WriteString(" WHEN ? THEN ?")
+				args = append(args, bi.item.DocumentID, v)
+			}
+		}
+		sb.WriteString(fmt.Sprintf(" ELSE %s END", col))
+		sets = append(sets, sb.String())
+	}
+
+	placeholders := make([]string, len(rows))
+	ids := make([]interface{}, len(rows))
+	for i, bi := range rows {
+		placeholders[i] = "?"
+		ids[i] = bi.item.DocumentID
+	}
+	args = append(args, ids...)
+
+	sql := fmt.Sprintf("UPDATE %s SET %s WHERE id IN (%s)",
+		tableName, strings.Join(sets, ", "), strings.Join(placeholders, ","))
+
+	return tx.Exec(sql, args...).Error
+}
+
+// execBulkDelete compiles items into one DELETE ... WHERE id IN (...).
+func execBulkDelete(tx *gorm.DB, tableName string, items []bulkItem) error {
+	ids := make([]interface{}, 0, len(items))
+	placeholders := make([]string, 0, len(items))
+	for _, bi := range items {
+		if bi.item.DocumentID == "" {
+			continue
+		}
+		ids = append(ids, bi.item.DocumentID)
+		placeholders = append(placeholders, "?")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	sql := fmt.Sprintf("DELETE FROM %s WHERE id IN (%s)", tableName, strings.Join(placeholders, ","))
+	return tx.Exec(sql, ids...).Error
+}
+
+// unionColumns returns the sorted union of every item's document columns,
+// so a single multi-row statement can share one column list even when
+// individual documents don't all set the same fields.
+func unionColumns(items []bulkItem) []string {
+	colSet := make(map[string]struct{})
+	for _, bi := range items {
+		for col := range bi.doc {
+			colSet[col] = struct{}{}
+		}
+		if bi.item.DocumentID != "" {
+			colSet["id"] = struct{}{}
+		}
+	}
+
+	columns := make([]string, 0, len(colSet))
+	for col := range colSet {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	return columns
 }